@@ -2,7 +2,10 @@ module github.com/20TB-ZipBomb/GGJ_Platform
 
 go 1.21.6
 
-require github.com/gorilla/websocket v1.5.1
+require (
+	github.com/gorilla/websocket v1.5.1
+	golang.org/x/text v0.13.0
+)
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
@@ -9,7 +9,8 @@ import (
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils"
 )
 
-var sugar *zap.SugaredLogger
+// Messages logged before the logger is initialized, e.g., while the game configuration loads on mount, go to a plain production logger
+var sugar *zap.SugaredLogger = zap.Must(zap.NewProduction()).Sugar()
 
 func Init() {
 	config := useEnvConfig()
@@ -2,11 +2,17 @@ package utils
 
 import (
 	"flag"
+	"strconv"
 )
 
-// Evaluates if the server is currently running in production.
+// Evaluates if the server is currently running in production, packages used without the server's flags run in development.
 func IsProductionEnv() bool {
-	return SanitizeEnvFlag(flag.Lookup("env").Value.String()) == "prod"
+	f := flag.Lookup("env")
+	if f == nil {
+		return false
+	}
+
+	return SanitizeEnvFlag(f.Value.String()) == "prod"
 }
 
 // Ensures that the server runs in development if the environment command-line argument is malformed.
@@ -20,5 +26,25 @@ func SanitizeEnvFlag(env string) string {
 
 // Evaluates if the server is currently running with verbose logging enabled.
 func IsVerboseLoggingEnabled() bool {
-	return flag.Lookup("verbose").Value.String() == "true"
+	f := flag.Lookup("verbose")
+	if f == nil {
+		return false
+	}
+
+	return f.Value.String() == "true"
+}
+
+// Retrieves the seed passed on the command-line, 0 if none was passed.
+func GetSeedFlag() int64 {
+	f := flag.Lookup("seed")
+	if f == nil {
+		return 0
+	}
+
+	seed, err := strconv.ParseInt(f.Value.String(), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return seed
 }
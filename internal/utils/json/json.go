@@ -18,6 +18,14 @@ func UnmarshalJSON[T any](source []byte) T {
 	return target
 }
 
+// Generic wrapper for unmarshalling JSON that reports failures to the caller instead of logging them.
+func TryUnmarshalJSON[T any](source []byte) (T, error) {
+	var target T
+	err := json.Unmarshal(source, &target)
+
+	return target, err
+}
+
 // Generic wrapper for marshalling JSON with custom struct definitions.
 func MarshalJSON[T any](t *T) string {
 	bytes, err := json.Marshal(t)
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	tokenLengthBytes = 16
+)
+
+// Generates a random hex-encoded token suitable for identifying a session.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenLengthBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -13,6 +13,7 @@ import (
 
 var env = flag.String("env", "dev", "server environment")
 var verbose = flag.Bool("verbose", false, "enables verbose logging")
+var seed = flag.Int64("seed", 0, "fixes the seed used to shuffle and deal every game, for reproducing games")
 
 const (
 	envFile = "config/.env"
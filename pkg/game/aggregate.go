@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Names of the score aggregation strategies that can be configured.
+const (
+	SumAggregator         = "sum"
+	MeanAggregator        = "mean"
+	MedianAggregator      = "median"
+	TrimmedMeanAggregator = "trimmed_mean"
+	DropHighLowAggregator = "drop_high_low"
+)
+
+// Combines the scores that judges gave a single performance into the score the performer earns for it.
+type ScoreAggregator interface {
+	// Aggregates the passed scores in cents, a performance without any scores earns zero.
+	Aggregate(scores []int) int
+}
+
+// Adds every score together, so performances judged by more judges can earn more.
+type SumScoreAggregator struct{}
+
+// Averages the scores.
+type MeanScoreAggregator struct{}
+
+// Takes the middle score, averaging the two middle scores when there's an even number of them.
+type MedianScoreAggregator struct{}
+
+// Averages the scores after discarding a fraction of the highest and lowest scores.
+// The number of scores discarded from each end is rounded up, e.g., a 0.25 cutoff drops the highest and lowest of three scores,
+// but at least one score is always kept.
+type TrimmedMeanScoreAggregator struct {
+	Cutoff float64
+}
+
+// Averages the scores after discarding the single highest and lowest score, if there are at least three scores.
+type DropHighLowScoreAggregator struct{}
+
+// Creates the score aggregator with the passed name, returns an error if no strategy has that name.
+// The cutoff is the fraction of scores discarded from each end by the trimmed mean.
+func CreateScoreAggregator(name string, cutoff float64) (ScoreAggregator, error) {
+	switch name {
+	case SumAggregator:
+		return &SumScoreAggregator{}, nil
+	case MeanAggregator:
+		return &MeanScoreAggregator{}, nil
+	case MedianAggregator:
+		return &MedianScoreAggregator{}, nil
+	case TrimmedMeanAggregator:
+		if cutoff < 0 || cutoff >= 0.5 {
+			return nil, fmt.Errorf("Trimmed mean cutoff must be at least 0 and less than 0.5, got %v.", cutoff)
+		}
+
+		return &TrimmedMeanScoreAggregator{Cutoff: cutoff}, nil
+	case DropHighLowAggregator:
+		return &DropHighLowScoreAggregator{}, nil
+	default:
+		return nil, fmt.Errorf("Unknown score aggregator %q.", name)
+	}
+}
+
+func (a *SumScoreAggregator) Aggregate(scores []int) int {
+	sum := 0
+	for _, score := range scores {
+		sum += score
+	}
+
+	return sum
+}
+
+func (a *MeanScoreAggregator) Aggregate(scores []int) int {
+	return meanOfScores(scores)
+}
+
+func (a *MedianScoreAggregator) Aggregate(scores []int) int {
+	sorted := sortScores(scores)
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return meanOfScores(sorted[n/2-1 : n/2+1])
+}
+
+func (a *TrimmedMeanScoreAggregator) Aggregate(scores []int) int {
+	sorted := sortScores(scores)
+	trim := min(int(math.Ceil(float64(len(sorted))*a.Cutoff)), (len(sorted)-1)/2)
+
+	return meanOfScores(sorted[trim : len(sorted)-trim])
+}
+
+func (a *DropHighLowScoreAggregator) Aggregate(scores []int) int {
+	sorted := sortScores(scores)
+	if len(sorted) < 3 {
+		return meanOfScores(sorted)
+	}
+
+	return meanOfScores(sorted[1 : len(sorted)-1])
+}
+
+// Averages the scores, rounding to the nearest cent.
+func meanOfScores(scores []int) int {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	sum := (&SumScoreAggregator{}).Aggregate(scores)
+
+	return int(math.Round(float64(sum) / float64(len(scores))))
+}
+
+// Returns a sorted copy of the scores, leaving the passed scores untouched.
+func sortScores(scores []int) []int {
+	sorted := append([]int(nil), scores...)
+	sort.Ints(sorted)
+
+	return sorted
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScoreAggregators(t *testing.T) {
+	tests := []struct {
+		aggregator string
+		cutoff     float64
+		scores     []int
+		want       int
+	}{
+		{aggregator: SumAggregator, scores: nil, want: 0},
+		{aggregator: SumAggregator, scores: []int{100, 250, 50}, want: 400},
+		{aggregator: MeanAggregator, scores: nil, want: 0},
+		{aggregator: MeanAggregator, scores: []int{100, 250, 50}, want: 133},
+		{aggregator: MeanAggregator, scores: []int{1, 2}, want: 2},
+		{aggregator: MedianAggregator, scores: nil, want: 0},
+		{aggregator: MedianAggregator, scores: []int{300, 100, 200}, want: 200},
+		{aggregator: MedianAggregator, scores: []int{400, 100, 200, 900}, want: 300},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: nil, want: 0},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: []int{500}, want: 500},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: []int{100, 300}, want: 200},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: []int{0, 200, 1000}, want: 200},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: []int{0, 100, 300, 1000}, want: 200},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.25, scores: []int{0, 0, 100, 300, 1000, 1000}, want: 200},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0, scores: []int{0, 200, 1000}, want: 400},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.49, scores: []int{0, 100, 300, 1000}, want: 200},
+		{aggregator: DropHighLowAggregator, scores: nil, want: 0},
+		{aggregator: DropHighLowAggregator, scores: []int{100, 300}, want: 200},
+		{aggregator: DropHighLowAggregator, scores: []int{0, 200, 1000}, want: 200},
+		{aggregator: DropHighLowAggregator, scores: []int{0, 100, 300, 1000}, want: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v %v", tt.aggregator, tt.cutoff, tt.scores), func(t *testing.T) {
+			a, err := CreateScoreAggregator(tt.aggregator, tt.cutoff)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			scores := append([]int(nil), tt.scores...)
+			if got := a.Aggregate(scores); got != tt.want {
+				t.Errorf("aggregated %v to %d, want %d", tt.scores, got, tt.want)
+			}
+			if fmt.Sprint(scores) != fmt.Sprint(tt.scores) {
+				t.Errorf("aggregating reordered the scores to %v", scores)
+			}
+		})
+	}
+}
+
+func TestCreateScoreAggregatorRejectsInvalidStrategies(t *testing.T) {
+	tests := []struct {
+		aggregator string
+		cutoff     float64
+	}{
+		{aggregator: "average"},
+		{aggregator: ""},
+		{aggregator: TrimmedMeanAggregator, cutoff: -0.1},
+		{aggregator: TrimmedMeanAggregator, cutoff: 0.5},
+	}
+
+	for _, tt := range tests {
+		if _, err := CreateScoreAggregator(tt.aggregator, tt.cutoff); err == nil {
+			t.Errorf("created %q aggregator with cutoff %v, want an error", tt.aggregator, tt.cutoff)
+		}
+	}
+}
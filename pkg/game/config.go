@@ -14,18 +14,63 @@ const (
 )
 
 type GameConfig struct {
-	Limits LimitConfig `yaml:"limits"`
-	Times  TimeConfig  `yaml:"times"`
+	Limits       LimitConfig      `yaml:"limits"`
+	Times        TimeConfig       `yaml:"times"`
+	LobbyCodes   LobbyCodeConfig  `yaml:"lobby_codes"`
+	Moderation   ModerationConfig `yaml:"moderation"`
+	Network      NetworkConfig    `yaml:"network"`
+	Random       RandomConfig     `yaml:"random"`
+	Scoring      ScoringConfig    `yaml:"scoring"`
+	FallbackJobs []string         `yaml:"fallback_jobs"`
 }
 
 type LimitConfig struct {
-	MinimumNumberOfPlayers int `yaml:"minimum_number_of_players"`
+	MinimumNumberOfPlayers int  `yaml:"minimum_number_of_players"`
+	MaximumNumberOfPlayers int  `yaml:"maximum_number_of_players"`
+	MinimumNameLength      int  `yaml:"minimum_name_length"`
+	MaximumNameLength      int  `yaml:"maximum_name_length"`
+	SuffixDuplicateNames   bool `yaml:"suffix_duplicate_names"`
+	MinimumJobLength       int  `yaml:"minimum_job_length"`
+	MaximumJobLength       int  `yaml:"maximum_job_length"`
+	InterceptionsPerPlayer int  `yaml:"interceptions_per_player"`
+	MinimumScoreInCents    int  `yaml:"minimum_score_in_cents"`
+	MaximumScoreInCents    int  `yaml:"maximum_score_in_cents"`
+	NumberOfRounds         int  `yaml:"number_of_rounds"`
 }
 
 type TimeConfig struct {
-	ImprovRoundDurationSeconds   int `yaml:"improv_round_duration_seconds"`
-	InterceptionTimeAddedSeconds int `yaml:"interception_time_added_seconds"`
-	IntermissionDurationSeconds  int `yaml:"intermission_duration_seconds"`
+	ImprovRoundDurationSeconds      int `yaml:"improv_round_duration_seconds"`
+	InterceptionTimeAddedSeconds    int `yaml:"interception_time_added_seconds"`
+	IntermissionDurationSeconds     int `yaml:"intermission_duration_seconds"`
+	ReconnectGracePeriodSeconds     int `yaml:"reconnect_grace_period_seconds"`
+	HostReconnectGracePeriodSeconds int `yaml:"host_reconnect_grace_period_seconds"`
+	JobSubmissionDurationSeconds    int `yaml:"job_submission_duration_seconds"`
+	CardSelectionDurationSeconds    int `yaml:"card_selection_duration_seconds"`
+	ScoringDurationSeconds          int `yaml:"scoring_duration_seconds"`
+	InterceptionCooldownSeconds     int `yaml:"interception_cooldown_seconds"`
+}
+
+type NetworkConfig struct {
+	TrustedProxy bool `yaml:"trusted_proxy"`
+}
+
+type ModerationConfig struct {
+	WordlistPath string `yaml:"wordlist_path"`
+}
+
+type ScoringConfig struct {
+	Aggregator        string  `yaml:"aggregator"`
+	TrimmedMeanCutoff float64 `yaml:"trimmed_mean_cutoff"`
+}
+
+type RandomConfig struct {
+	Seed int64 `yaml:"seed"`
+}
+
+type LobbyCodeConfig struct {
+	Alphabet        string `yaml:"alphabet"`
+	Length          int    `yaml:"length"`
+	CooldownSeconds int    `yaml:"cooldown_seconds"`
 }
 
 // Attempts to read the local game configuration, returns a default if retrieval fails.
@@ -45,11 +90,53 @@ func GetDefaultGameConfig() *GameConfig {
 	return &GameConfig{
 		Limits: LimitConfig{
 			MinimumNumberOfPlayers: 3,
+			MaximumNumberOfPlayers: 8,
+			MinimumNameLength:      1,
+			MaximumNameLength:      16,
+			SuffixDuplicateNames:   true,
+			MinimumJobLength:       1,
+			MaximumJobLength:       40,
+			InterceptionsPerPlayer: 2,
+			MinimumScoreInCents:    0,
+			MaximumScoreInCents:    1000000,
+			NumberOfRounds:         1,
 		},
 		Times: TimeConfig{
-			ImprovRoundDurationSeconds:   30,
-			InterceptionTimeAddedSeconds: 30,
-			IntermissionDurationSeconds:  10,
+			ImprovRoundDurationSeconds:      30,
+			InterceptionTimeAddedSeconds:    30,
+			IntermissionDurationSeconds:     10,
+			ReconnectGracePeriodSeconds:     60,
+			HostReconnectGracePeriodSeconds: 120,
+			JobSubmissionDurationSeconds:    120,
+			CardSelectionDurationSeconds:    45,
+			ScoringDurationSeconds:          30,
+			InterceptionCooldownSeconds:     10,
+		},
+		LobbyCodes: LobbyCodeConfig{
+			Alphabet:        "ABCDEFGHJKLMNPQRSTUVWXYZ",
+			Length:          4,
+			CooldownSeconds: 600,
+		},
+		Scoring: ScoringConfig{
+			Aggregator:        SumAggregator,
+			TrimmedMeanCutoff: 0.25,
+		},
+		Moderation: ModerationConfig{
+			WordlistPath: "config/wordlist.txt",
+		},
+		FallbackJobs: []string{
+			"Astronaut",
+			"Barista",
+			"Beekeeper",
+			"Chef",
+			"Dentist",
+			"Firefighter",
+			"Lifeguard",
+			"Magician",
+			"Mime",
+			"Pilot",
+			"Plumber",
+			"Zookeeper",
 		},
 	}
 }
@@ -69,6 +156,66 @@ func (cfg *GameConfig) GetTypedIntermissionDurationSeconds() time.Duration {
 	return time.Duration(cfg.Times.IntermissionDurationSeconds) * time.Second
 }
 
+// Retrieves the window in which a disconnected web client can resume its session as a time.Duration.
+func (cfg *GameConfig) GetTypedReconnectGracePeriodSeconds() time.Duration {
+	return time.Duration(cfg.Times.ReconnectGracePeriodSeconds) * time.Second
+}
+
+// Retrieves the window in which a disconnected game client can reclaim its lobby as a time.Duration.
+func (cfg *GameConfig) GetTypedHostReconnectGracePeriodSeconds() time.Duration {
+	return time.Duration(cfg.Times.HostReconnectGracePeriodSeconds) * time.Second
+}
+
+// Retrieves the job submission deadline as a time.Duration.
+func (cfg *GameConfig) GetTypedJobSubmissionDurationSeconds() time.Duration {
+	return time.Duration(cfg.Times.JobSubmissionDurationSeconds) * time.Second
+}
+
+// Retrieves the card selection deadline as a time.Duration.
+func (cfg *GameConfig) GetTypedCardSelectionDurationSeconds() time.Duration {
+	return time.Duration(cfg.Times.CardSelectionDurationSeconds) * time.Second
+}
+
+// Retrieves the scoring deadline as a time.Duration.
+func (cfg *GameConfig) GetTypedScoringDurationSeconds() time.Duration {
+	return time.Duration(cfg.Times.ScoringDurationSeconds) * time.Second
+}
+
+// Retrieves the cooldown between interceptions as a time.Duration.
+func (cfg *GameConfig) GetTypedInterceptionCooldownSeconds() time.Duration {
+	return time.Duration(cfg.Times.InterceptionCooldownSeconds) * time.Second
+}
+
+// Retrieves the number of rounds in a game, every game has at least one round.
+func (cfg *GameConfig) GetNumberOfRounds() int {
+	return max(cfg.Limits.NumberOfRounds, 1)
+}
+
+// Retrieves the jobs used to fill in for players that don't submit in time, falls back to the default deck if none are configured.
+func (cfg *GameConfig) GetFallbackJobs() []string {
+	if len(cfg.FallbackJobs) == 0 {
+		return GetDefaultGameConfig().FallbackJobs
+	}
+
+	return cfg.FallbackJobs
+}
+
+// Retrieves the configured score aggregation strategy, falls back to summing scores if it's misconfigured.
+func (cfg *GameConfig) GetScoreAggregator() ScoreAggregator {
+	agg, err := CreateScoreAggregator(cfg.Scoring.Aggregator, cfg.Scoring.TrimmedMeanCutoff)
+	if err != nil {
+		logger.Errorf("[config] Failed to create score aggregator: %v, summing scores instead", err)
+		return &SumScoreAggregator{}
+	}
+
+	return agg
+}
+
+// Retrieves the cooldown before a released lobby code can be reused as a time.Duration.
+func (cfg *GameConfig) GetTypedLobbyCodeCooldownSeconds() time.Duration {
+	return time.Duration(cfg.LobbyCodes.CooldownSeconds) * time.Second
+}
+
 // Tries to read the config file and decode it into the GameConfig struct.
 func tryReadConfigFile(cfg *GameConfig) error {
 	wd, err := os.Getwd()
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+// Deals the job pool out into balanced hands for the passed players, avoiding dealing anyone a card they authored.
+// Hands differ in size by at most one card, and the last card in each hand is the player's job card.
+// When it's impossible to avoid dealing someone their own cards, as few cards as possible are self-dealt.
+func dealHands(r *rand.Rand, players []uuid.UUID, pool []*pack.Card, authors map[*pack.Card]uuid.UUID) map[uuid.UUID][]*pack.Card {
+	hands := make(map[uuid.UUID][]*pack.Card)
+	if len(players) == 0 {
+		return hands
+	}
+
+	// Shuffling the players decides who receives the larger hands and whose cards end up where,
+	// the larger hands go to the players that authored the fewest cards so that they have more room for other players' cards
+	authorOrder := append(append(make([]uuid.UUID, 0, len(players)+1), players...), uuid.Nil)
+	players = append([]uuid.UUID(nil), players...)
+	r.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+
+	authored := make(map[uuid.UUID]int)
+	for _, card := range pool {
+		authored[authors[card]]++
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		return authored[players[i]] < authored[players[j]]
+	})
+
+	capacities := make([]int, len(players))
+	for i := range players {
+		capacities[i] = len(pool) / len(players)
+		if i < len(pool)%len(players) {
+			capacities[i]++
+		}
+	}
+
+	// Cards are grouped by author, cards from players that aren't being dealt to can go to anyone
+	groups := make(map[uuid.UUID][]*pack.Card)
+	for _, card := range pool {
+		groups[authors[card]] = append(groups[authors[card]], card)
+	}
+
+	// Groups are walked in the passed player order rather than map order, so the deal only depends on the random source
+	groupAuthors := make([]uuid.UUID, 0, len(groups))
+	for _, author := range authorOrder {
+		if cards, ok := groups[author]; ok {
+			r.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+			groupAuthors = append(groupAuthors, author)
+		}
+	}
+
+	// Route as many cards as possible to players that didn't author them, whatever's left can only go back to its author
+	flow := maximizeForeignCards(groupAuthors, groups, players, capacities)
+	for g, author := range groupAuthors {
+		for p, player := range players {
+			n := flow[g][p]
+			hands[player] = append(hands[player], groups[author][:n]...)
+			groups[author] = groups[author][n:]
+			capacities[p] -= n
+		}
+	}
+
+	for _, author := range groupAuthors {
+		for p, player := range players {
+			n := min(capacities[p], len(groups[author]))
+			hands[player] = append(hands[player], groups[author][:n]...)
+			groups[author] = groups[author][n:]
+			capacities[p] -= n
+		}
+	}
+
+	for _, player := range players {
+		hand := hands[player]
+		r.Shuffle(len(hand), func(i, j int) { hand[i], hand[j] = hand[j], hand[i] })
+
+		// Prefer a card the player didn't author as their job card
+		for i := len(hand) - 1; i >= 0; i-- {
+			if authors[hand[i]] != player {
+				hand[i], hand[len(hand)-1] = hand[len(hand)-1], hand[i]
+				break
+			}
+		}
+	}
+
+	return hands
+}
+
+// Finds how many cards from each author group to deal to each player, maximizing the number of cards dealt to players that didn't author them.
+// This is a max flow from the author groups, through every player except the author, into the player's hand capacity.
+func maximizeForeignCards(groupAuthors []uuid.UUID, groups map[uuid.UUID][]*pack.Card, players []uuid.UUID, capacities []int) [][]int {
+	flow := make([][]int, len(groupAuthors))
+	supply := make([]int, len(groupAuthors))
+	for g, author := range groupAuthors {
+		flow[g] = make([]int, len(players))
+		supply[g] = len(groups[author])
+	}
+
+	remaining := append([]int(nil), capacities...)
+
+	// Augmenting paths alternate between groups and players, moving along a player's existing flow in reverse
+	var augment func(g int, visited []bool) bool
+	augment = func(g int, visited []bool) bool {
+		for p, player := range players {
+			if player == groupAuthors[g] || visited[p] {
+				continue
+			}
+			visited[p] = true
+
+			if remaining[p] > 0 {
+				remaining[p]--
+				flow[g][p]++
+				return true
+			}
+
+			for other := range groupAuthors {
+				if other != g && flow[other][p] > 0 && augment(other, visited) {
+					flow[other][p]--
+					flow[g][p]++
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	for g := range groupAuthors {
+		for supply[g] > 0 && augment(g, make([]bool, len(players))) {
+			supply[g]--
+		}
+	}
+
+	return flow
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+func TestDealHands(t *testing.T) {
+	tests := []struct {
+		name string
+		// Number of cards each player authored
+		authored []int
+		// Number of cards authored by someone who isn't being dealt to, e.g., a player that left
+		unowned int
+		// Number of cards that can't avoid going back to their author
+		wantOwnCards int
+	}{
+		{name: "even submissions", authored: []int{4, 4, 4}},
+		{name: "full lobby", authored: []int{9, 9, 9, 9, 9, 9, 9, 9}},
+		{name: "unowned cards", authored: []int{4, 4, 4}, unowned: 5},
+		{name: "uneven pool", authored: []int{3, 4, 4}},
+		{name: "one heavy author", authored: []int{7, 1, 0, 0}, unowned: 1},
+		{name: "single author", authored: []int{12, 0, 0}, wantOwnCards: 4},
+		{name: "two players", authored: []int{5, 1}, wantOwnCards: 2},
+		{name: "solo player", authored: []int{3}, wantOwnCards: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := make([]uuid.UUID, 0, len(tt.authored))
+			pool := make([]*pack.Card, 0)
+			authors := make(map[*pack.Card]uuid.UUID)
+			for _, n := range tt.authored {
+				player := uuid.New()
+				players = append(players, player)
+
+				for i := 0; i < n; i++ {
+					card := &pack.Card{CardID: uuid.New()}
+					pool = append(pool, card)
+					authors[card] = player
+				}
+			}
+			for i := 0; i < tt.unowned; i++ {
+				pool = append(pool, &pack.Card{CardID: uuid.New()})
+			}
+
+			// The guarantees hold for every shuffle, not just a lucky one
+			for seed := int64(0); seed < 100; seed++ {
+				hands := dealHands(rand.New(rand.NewSource(seed)), players, pool, authors)
+
+				dealt := make(map[*pack.Card]bool)
+				ownCards, smallest, largest := 0, len(pool), 0
+				for _, player := range players {
+					hand := hands[player]
+					smallest = min(smallest, len(hand))
+					largest = max(largest, len(hand))
+
+					for _, card := range hand {
+						if dealt[card] {
+							t.Fatalf("seed %d: card %s was dealt twice", seed, card.CardID)
+						}
+						dealt[card] = true
+
+						if authors[card] == player {
+							ownCards++
+						}
+					}
+
+					if tt.wantOwnCards == 0 && len(hand) > 0 && authors[hand[len(hand)-1]] == player {
+						t.Fatalf("seed %d: player was dealt their own job as their job card", seed)
+					}
+				}
+
+				if len(dealt) != len(pool) {
+					t.Fatalf("seed %d: dealt %d of %d cards", seed, len(dealt), len(pool))
+				}
+				if largest-smallest > 1 {
+					t.Fatalf("seed %d: hand sizes range from %d to %d", seed, smallest, largest)
+				}
+				if ownCards != tt.wantOwnCards {
+					t.Fatalf("seed %d: dealt %d cards back to their authors, want %d", seed, ownCards, tt.wantOwnCards)
+				}
+			}
+		})
+	}
+}
@@ -6,24 +6,29 @@ import (
 
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
 	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
 )
 
 type ImprovSession struct {
-	PlayerQueue  []*PlayerState
-	SessionTimer *time.Timer
+	PlayerQueue        []*PlayerState
+	SessionTimer       *PausableTimer
+	Scores             map[uuid.UUID]int
+	Voters             map[uuid.UUID]int
+	LastInterceptionAt time.Time
 }
 
 type ImprovSessionTimerCallback func()
 
-// Creates an improv session using a list of players, these players are shuffled and placed in a queue.
-func CreateImprovSession(players []*PlayerState) *ImprovSession {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+// Creates an improv session using a list of players, these players are shuffled with the passed source and placed in a queue.
+func CreateImprovSession(players []*PlayerState, r *rand.Rand) *ImprovSession {
 	r.Shuffle(len(players), func(i, j int) {
 		players[i], players[j] = players[j], players[i]
 	})
 
 	return &ImprovSession{
 		PlayerQueue: players,
+		Scores:      make(map[uuid.UUID]int),
+		Voters:      make(map[uuid.UUID]int),
 	}
 }
 
@@ -34,23 +39,13 @@ func (is *ImprovSession) GetNumberOfPlayersLeftToImprov() int {
 
 // Retrieves the player that is currently presenting (the player at the front of the queue)
 func (is *ImprovSession) GetCurrentImprovPlayer() *PlayerState {
-	if is.PlayerQueue == nil {
+	if len(is.PlayerQueue) == 0 {
 		return nil
 	}
 
 	return is.PlayerQueue[0]
 }
 
-// Retrieves the number of scores submitted for the currently improv'ing player.
-func (is *ImprovSession) GetNumberOfScoresSubmittedForCurrentPlayer() int {
-	if is.GetCurrentImprovPlayer() == nil {
-		logger.Error("[server] Failed to retrieve the number of scores submitted for the current player, no players are on the queue!")
-		return -1
-	}
-
-	return is.GetCurrentImprovPlayer().NumberOfScoresSubmitted
-}
-
 // Pops the top player off the improv queue.
 func (is *ImprovSession) PopPlayerOnQueue() *PlayerState {
 	if is.PlayerQueue == nil || len(is.PlayerQueue) == 0 {
@@ -59,25 +54,53 @@ func (is *ImprovSession) PopPlayerOnQueue() *PlayerState {
 
 	poppedPlayer := is.PlayerQueue[0]
 	is.PlayerQueue = is.PlayerQueue[1:]
+	is.Scores = make(map[uuid.UUID]int)
+	is.Voters = make(map[uuid.UUID]int)
 
 	return poppedPlayer
 }
 
+// Removes a player from the improv queue, the player at the front of the queue is kept if keepCurrent is set.
+func (is *ImprovSession) RemovePlayerFromQueue(id uuid.UUID, keepCurrent bool) {
+	queue := make([]*PlayerState, 0, len(is.PlayerQueue))
+	for i, ps := range is.PlayerQueue {
+		if ps.UUID != id || (i == 0 && keepCurrent) {
+			queue = append(queue, ps)
+		}
+	}
+
+	is.PlayerQueue = queue
+}
+
+// Checks if the judge with the passed UUID has scored the currently improv'ing player.
+func (is *ImprovSession) HasJudgeScored(judge uuid.UUID) bool {
+	_, ok := is.Scores[judge]
+	return ok
+}
+
+// Withdraws the score a judge gave the currently improv'ing player, e.g., when the judge is kicked before scoring finishes.
+func (is *ImprovSession) WithdrawScore(judge uuid.UUID) {
+	player := is.GetCurrentImprovPlayer()
+	score, ok := is.Scores[judge]
+	if player == nil || !ok {
+		return
+	}
+
+	player.RawScoreInCents -= score
+	player.NumberOfScoresSubmitted -= 1
+	delete(is.Scores, judge)
+}
+
 // Starts a timer for the current improv session.
 func (is *ImprovSession) StartTimerForSession(cb ImprovSessionTimerCallback) {
-	if is.SessionTimer != nil {
+	if is.SessionTimer.IsRunning() {
 		logger.Warn("Tried to start a new round of improv but the timer is already going, ignoring.")
 		return
 	}
 
 	t := Config.GetTypedImprovRoundDurationSeconds()
 	logger.Debugf("TIMER: %s", t.String())
-	is.SessionTimer = time.NewTimer(t)
-	<-is.SessionTimer.C
-
-	cb()
-
-	is.SessionTimer = nil
+	is.SessionTimer = StartPausableTimer(t, cb)
 }
 
 // Resets the improv timer to a time, used during interceptions.
@@ -85,9 +108,105 @@ func (is *ImprovSession) ResetSessionTimer(resetTime time.Duration) {
 	is.SessionTimer.Reset(resetTime)
 }
 
-// Applies a score submission message's data to this player's stats.
-func (is *ImprovSession) SubmitScoreForPlayer(ss *pack.ScoreSubmissionMessage) {
+// Pauses the improv timer if a round is in progress.
+func (is *ImprovSession) PauseSessionTimer() bool {
+	return is.SessionTimer.Pause()
+}
+
+// Resumes the improv timer with the time that was remaining when it was paused.
+func (is *ImprovSession) ResumeSessionTimer() bool {
+	return is.SessionTimer.Resume()
+}
+
+// Stops the improv timer without finishing the round, used when the host skips a player.
+func (is *ImprovSession) StopSessionTimer() {
+	is.SessionTimer.Stop()
+}
+
+// Retrieves the time remaining in the current improv round.
+func (is *ImprovSession) GetSessionTimeRemaining() time.Duration {
+	return is.SessionTimer.Remaining()
+}
+
+// Records an interception played against the currently improv'ing player.
+func (is *ImprovSession) RecordInterceptionForPlayer() {
+	if player := is.GetCurrentImprovPlayer(); player != nil {
+		player.NumberOfInterceptionsReceived += 1
+	}
+}
+
+// Applies a score submission message's data from a judge to this player's stats, returns an error if the judge is the performer.
+// Each judge has one vote per round, so a repeat vote replaces the judge's earlier vote and true is returned.
+func (is *ImprovSession) SubmitScoreForPlayer(judge uuid.UUID, ss *pack.ScoreSubmissionMessage) (bool, error) {
 	player := is.GetCurrentImprovPlayer()
-	player.ScoreInCents += ss.ScoreInCents
-	player.NumberOfScoresSubmitted += 1
+	if player == nil {
+		return false, pack.NewCodedError(pack.ErrorWrongPhase, "Score submission request was received, but nobody is being scored.")
+	}
+
+	if player.UUID == judge {
+		return false, pack.NewCodedError(pack.ErrorSelfScoring, "Score submission request was received, but players can't score their own improv.")
+	}
+
+	prev, replaced := is.Scores[judge]
+	if replaced {
+		player.RawScoreInCents -= prev
+	} else {
+		player.NumberOfScoresSubmitted += 1
+	}
+
+	player.RawScoreInCents += ss.ScoreInCents
+	is.Scores[judge] = ss.ScoreInCents
+
+	return replaced, nil
+}
+
+// Aggregates the scores the judges gave the current player this round and adds the result to their score, returns the round's score.
+func (is *ImprovSession) ApplyScoresForPlayer(agg ScoreAggregator) int {
+	player := is.GetCurrentImprovPlayer()
+	if player == nil {
+		return 0
+	}
+
+	scores := make([]int, 0, len(is.Scores))
+	for _, score := range is.Scores {
+		scores = append(scores, score)
+	}
+
+	roundScore := agg.Aggregate(scores)
+	player.ScoreInCents += roundScore
+
+	return roundScore
+}
+
+// Applies an audience member's salary vote to this player's audience award tally, returns false if they've already voted for this player.
+// Audience votes are kept apart from judges' scores and never count towards the score quorum.
+func (is *ImprovSession) SubmitAudienceVoteForPlayer(voter uuid.UUID, ss *pack.ScoreSubmissionMessage) bool {
+	player := is.GetCurrentImprovPlayer()
+	if player == nil {
+		return false
+	}
+
+	if _, voted := is.Voters[voter]; voted {
+		return false
+	}
+
+	player.AudienceScoreInCents += ss.ScoreInCents
+	player.NumberOfAudienceVotes += 1
+
+	is.Voters[voter] = ss.ScoreInCents
+
+	return true
+}
+
+// Withdraws the vote an audience member gave the currently improv'ing player, e.g., when they're kicked mid-performance.
+func (is *ImprovSession) WithdrawAudienceVote(voter uuid.UUID) {
+	player := is.GetCurrentImprovPlayer()
+	vote, ok := is.Voters[voter]
+	if player == nil || !ok {
+		return
+	}
+
+	player.AudienceScoreInCents -= vote
+	player.NumberOfAudienceVotes -= 1
+	delete(is.Voters, voter)
 }
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"time"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+// A record of a player intercepting an improv performance with a card from their hand.
+type InterceptionRecord struct {
+	InterceptorUUID uuid.UUID
+	InterceptorName string
+	Card            *pack.Card
+}
+
+// Checks the interception rules for a player intercepting the current performer with a card from their hand.
+// If the interception is allowed, the card is moved from the interceptor's hand to the performer as their new role and returned,
+// otherwise an error describes the broken rule.
+func (s *State) InterceptWithCard(interceptor uuid.UUID, cardID uuid.UUID) (*pack.Card, error) {
+	performer := s.ImprovSession.GetCurrentImprovPlayer()
+	if performer == nil {
+		return nil, pack.NewCodedError(pack.ErrorWrongPhase, "Interception request was received, but nobody is performing.")
+	}
+
+	if performer.UUID == interceptor {
+		return nil, pack.NewCodedError(pack.ErrorSelfInterception, "Interception request was received, but players can't intercept their own improv.")
+	}
+
+	ps, ok := s.PlayersToPlayerState[interceptor]
+	if !ok {
+		return nil, pack.NewCodedError(pack.ErrorNotAPlayer, "Interception request was received, but the sender doesn't have a hand.")
+	}
+
+	if ps.NumberOfInterceptionsUsed >= Config.Limits.InterceptionsPerPlayer {
+		return nil, pack.NewCodedErrorf(pack.ErrorNoInterceptionsLeft, "Interception request was received, but players can only intercept %d times per game.", Config.Limits.InterceptionsPerPlayer)
+	}
+
+	if remaining := s.ImprovSession.InterceptionCooldownRemaining(); remaining > 0 {
+		return nil, pack.NewCodedErrorf(pack.ErrorInterceptionCooldown, "Interception request was received, but interceptions are cooling down for another %d seconds.", int(remaining.Round(time.Second).Seconds()))
+	}
+
+	// The card a player selected for their own improv isn't theirs to play
+	card := ps.FindDrawnCard(cardID)
+	if card == nil || card == ps.SelectedCard {
+		return nil, pack.NewCodedError(pack.ErrorUnknownCard, "Interception request was received, but the card isn't in the player's hand.")
+	}
+
+	// A role the player lost to an interception stays in their hand, but it's been played already
+	if ps.RetiredCards[card] {
+		return nil, pack.NewCodedError(pack.ErrorUnknownCard, "Interception request was received, but the card has already been played.")
+	}
+
+	ps.RemoveDrawnCard(card)
+	ps.NumberOfInterceptionsUsed += 1
+	s.retireCard(card)
+	s.ImprovSession.LastInterceptionAt = time.Now()
+	s.ImprovSession.RecordInterceptionForPlayer()
+
+	performer.RetiredCards[performer.SelectedCard] = true
+	performer.SelectedCard = card
+	performer.Interceptions = append(performer.Interceptions, &InterceptionRecord{
+		InterceptorUUID: interceptor,
+		InterceptorName: ps.Name,
+		Card:            card,
+	})
+
+	logger.Verbosef("[game] Player %s intercepted %s with %s.", interceptor.String(), performer.UUID.String(), card.CardID.String())
+
+	return card, nil
+}
+
+// Retrieves how long it is until the next interception is allowed in this improv session.
+func (is *ImprovSession) InterceptionCooldownRemaining() time.Duration {
+	if is.LastInterceptionAt.IsZero() {
+		return 0
+	}
+
+	if remaining := time.Until(is.LastInterceptionAt.Add(Config.GetTypedInterceptionCooldownSeconds())); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// Removes a card from the player's hand.
+func (ps *PlayerState) RemoveDrawnCard(card *pack.Card) {
+	for i, drawn := range ps.DrawnCards {
+		if drawn == card {
+			ps.DrawnCards = append(ps.DrawnCards[:i:i], ps.DrawnCards[i+1:]...)
+			return
+		}
+	}
+}
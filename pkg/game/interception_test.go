@@ -0,0 +1,42 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+func TestInterceptedRoleCantBeReplayed(t *testing.T) {
+	Config.Limits.InterceptionsPerPlayer = 2
+	Config.Times.InterceptionCooldownSeconds = 0
+
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	s := CreateGameState(players, rand.NewSource(1))
+	for _, player := range players {
+		hand := []*pack.Card{{CardID: uuid.New()}, {CardID: uuid.New()}}
+		s.CreatePlayerStateWithUUID(player, player.String(), hand, &pack.Card{CardID: uuid.New()})
+		s.PlayersToPlayerState[player].SelectedCard = hand[0]
+	}
+	s.ImprovSession = CreateImprovSession(s.GetPlayerStates(), s.rng)
+
+	performer := s.ImprovSession.GetCurrentImprovPlayer()
+	interceptor := s.ImprovSession.PlayerQueue[1]
+	role := performer.SelectedCard
+	if _, err := s.InterceptWithCard(interceptor.UUID, interceptor.DrawnCards[1].CardID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the next player is performing, the intercepted performer tries to play the role they lost
+	s.ImprovSession.PopPlayerOnQueue()
+	var ce *pack.CodedError
+	if _, err := s.InterceptWithCard(performer.UUID, role.CardID); !errors.As(err, &ce) || ce.Code != pack.ErrorUnknownCard {
+		t.Fatalf("replaying an intercepted role returned %v, want %s", err, pack.ErrorUnknownCard)
+	}
+
+	if _, err := s.InterceptWithCard(performer.UUID, performer.DrawnCards[1].CardID); err != nil {
+		t.Fatalf("playing an unplayed card was rejected: %v", err)
+	}
+}
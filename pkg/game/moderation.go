@@ -0,0 +1,120 @@
+package game
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+)
+
+// Reviews text submitted by players before it's shown to anyone else.
+type Moderator interface {
+	// Returns an error describing why the text was rejected, or nil if it's acceptable.
+	Review(text string) error
+}
+
+// Moderator consulted for every submitted job, this can be set to a different filter before the server starts.
+// If it's left unset, the configured wordlist is loaded the first time a job is submitted, as the logger isn't ready on mount.
+var JobModerator Moderator
+
+var loadJobModeratorOnce sync.Once
+
+// Retrieves the moderator consulted for submitted jobs, loading the configured wordlist if none was set.
+func GetJobModerator() Moderator {
+	loadJobModeratorOnce.Do(func() {
+		if JobModerator == nil {
+			JobModerator = LoadWordlistModerator(Config.Moderation.WordlistPath)
+		}
+	})
+
+	return JobModerator
+}
+
+// Rejects text containing any word or phrase from a wordlist, matching whole words regardless of case.
+type WordlistModerator struct {
+	phrases []string
+}
+
+// Creates a wordlist moderator from the passed words and phrases.
+func CreateWordlistModerator(phrases []string) *WordlistModerator {
+	wm := &WordlistModerator{
+		phrases: make([]string, 0, len(phrases)),
+	}
+
+	for _, phrase := range phrases {
+		if normalized := normalizeWords(phrase); normalized != "" {
+			wm.phrases = append(wm.phrases, normalized)
+		}
+	}
+
+	return wm
+}
+
+// Loads a wordlist moderator from a file with one word or phrase per line, lines starting with # are ignored.
+// An empty wordlist is used if the file can't be read.
+func LoadWordlistModerator(path string) *WordlistModerator {
+	phrases, err := tryReadWordlistFile(path)
+	if err != nil {
+		logger.Errorf("[config] Failed to load wordlist: %v, job text won't be filtered", err)
+		return CreateWordlistModerator(nil)
+	}
+
+	logger.Verbosef("[config] Loaded %d entries from wordlist %s.", len(phrases), path)
+	return CreateWordlistModerator(phrases)
+}
+
+// Checks the passed text against the wordlist.
+func (wm *WordlistModerator) Review(text string) error {
+	// Padding both sides lets phrases be matched on word boundaries with a plain substring search
+	padded := " " + normalizeWords(text) + " "
+
+	for _, phrase := range wm.phrases {
+		if strings.Contains(padded, " "+phrase+" ") {
+			return pack.NewCodedError(pack.ErrorJobRejected, "Job submission request was received, but the job contains a word that isn't allowed.")
+		}
+	}
+
+	return nil
+}
+
+// Lowercases text and reduces it to its words separated by single spaces, so punctuation can't be used to slip past the filter
+// or to make a duplicate job look different.
+func normalizeWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	return strings.Join(words, " ")
+}
+
+// Tries to read the wordlist file relative to the working directory.
+func tryReadWordlistFile(path string) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(wd, path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	phrases := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		phrases = append(phrases, line)
+	}
+
+	return phrases, scanner.Err()
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+)
+
+// Represents the phase of the game that a lobby is currently in.
+type Phase int
+
+const (
+	LobbyPhase Phase = iota
+	JobSubmissionPhase
+	CardSelectionPhase
+	ImprovPhase
+	ScoringPhase
+	IntermissionPhase
+	FinishedPhase
+)
+
+// Phases that can legally follow each phase.
+var phaseTransitions = map[Phase][]Phase{
+	LobbyPhase:         {JobSubmissionPhase},
+	JobSubmissionPhase: {CardSelectionPhase},
+	CardSelectionPhase: {ImprovPhase, FinishedPhase},
+	ImprovPhase:        {ScoringPhase, IntermissionPhase},
+	ScoringPhase:       {IntermissionPhase},
+	IntermissionPhase:  {ImprovPhase, JobSubmissionPhase, CardSelectionPhase, FinishedPhase},
+	FinishedPhase:      {},
+}
+
+// Phases in which each gameplay message is accepted from clients.
+var messagePhases = map[pack.MessageType][]Phase{
+	pack.GameStart:            {LobbyPhase},
+	pack.PlayAgain:            {FinishedPhase},
+	pack.JobSubmitted:         {JobSubmissionPhase},
+	pack.CardData:             {CardSelectionPhase},
+	pack.InterceptionCardData: {ImprovPhase},
+	pack.ScoreSubmission:      {ScoringPhase},
+	pack.HostPause:            {JobSubmissionPhase, CardSelectionPhase, ImprovPhase, ScoringPhase, IntermissionPhase},
+	pack.HostResume:           {JobSubmissionPhase, CardSelectionPhase, ImprovPhase, ScoringPhase, IntermissionPhase},
+	pack.HostSkipPlayer:       {ImprovPhase, ScoringPhase},
+}
+
+// Retrieves a readable name for the phase.
+func (p Phase) String() string {
+	switch p {
+	case LobbyPhase:
+		return "lobby"
+	case JobSubmissionPhase:
+		return "job_submission"
+	case CardSelectionPhase:
+		return "card_selection"
+	case ImprovPhase:
+		return "improv"
+	case ScoringPhase:
+		return "scoring"
+	case IntermissionPhase:
+		return "intermission"
+	case FinishedPhase:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// Checks if the game can move from this phase to the passed phase.
+func (p Phase) CanTransitionTo(next Phase) bool {
+	for _, allowed := range phaseTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Moves the game to the passed phase, returns an error if the transition isn't legal from the current phase.
+func (s *State) TransitionTo(next Phase) error {
+	if !s.Phase.CanTransitionTo(next) {
+		return fmt.Errorf("Invalid phase transition from %s to %s.", s.Phase, next)
+	}
+
+	logger.Verbosef("[game] Phase transition from %s to %s.", s.Phase, next)
+	s.Phase = next
+
+	return nil
+}
+
+// Checks if a message of the passed type is legal in this phase, returns an error if it isn't.
+func (p Phase) CheckMessageAllowed(mt pack.MessageType) error {
+	phases, ok := messagePhases[mt]
+	if !ok {
+		return nil
+	}
+
+	for _, allowed := range phases {
+		if allowed == p {
+			return nil
+		}
+	}
+
+	return pack.NewCodedErrorf(pack.ErrorWrongPhase, "Message %s isn't allowed during the %s phase.", mt, p)
+}
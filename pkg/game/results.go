@@ -0,0 +1,163 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+// A record of a single improv performance, kept for the post-game results.
+type RoundRecord struct {
+	Round                 int
+	PlayerUUID            uuid.UUID
+	SelectedCard          *pack.Card
+	ScoreInCents          int
+	NumberOfScores        int
+	NumberOfInterceptions int
+	Interceptions         []*InterceptionRecord
+	Skipped               bool
+}
+
+// Records the performance that just ended for the passed player, the round is credited with what they earned since their last performance.
+func (s *State) RecordRound(ps *PlayerState, skipped bool) *RoundRecord {
+	rr := &RoundRecord{
+		Round:                 s.Round,
+		PlayerUUID:            ps.UUID,
+		SelectedCard:          ps.SelectedCard,
+		ScoreInCents:          ps.ScoreInCents,
+		NumberOfScores:        ps.NumberOfScoresSubmitted,
+		NumberOfInterceptions: ps.NumberOfInterceptionsReceived,
+		Skipped:               skipped,
+	}
+
+	for _, prev := range s.RoundHistory {
+		if prev.PlayerUUID == ps.UUID {
+			rr.ScoreInCents -= prev.ScoreInCents
+			rr.NumberOfScores -= prev.NumberOfScores
+			rr.NumberOfInterceptions -= prev.NumberOfInterceptions
+		}
+	}
+
+	// Interceptions are logged in order, so the ones from this round are the ones the previous rounds didn't claim
+	rr.Interceptions = ps.Interceptions[len(ps.Interceptions)-rr.NumberOfInterceptions:]
+
+	s.RoundHistory = append(s.RoundHistory, rr)
+
+	return rr
+}
+
+// Retrieves the average of the scores judges gave the player in cents, before aggregation. Players without any scores average zero.
+func (ps *PlayerState) AverageScoreInCents() int {
+	if ps.NumberOfScoresSubmitted == 0 {
+		return 0
+	}
+
+	return ps.RawScoreInCents / ps.NumberOfScoresSubmitted
+}
+
+// Retrieves the average audience vote the player received in cents, players without any votes average zero.
+func (ps *PlayerState) AverageAudienceScoreInCents() int {
+	if ps.NumberOfAudienceVotes == 0 {
+		return 0
+	}
+
+	return ps.AudienceScoreInCents / ps.NumberOfAudienceVotes
+}
+
+// Retrieves the player with the highest average audience vote, ties are broken by UUID. Returns nil if the audience didn't vote.
+func (s *State) GetAudienceAwardWinner() *PlayerState {
+	var winner *PlayerState
+
+	for _, ps := range s.PlayersToPlayerState {
+		if ps.NumberOfAudienceVotes == 0 {
+			continue
+		}
+
+		if winner == nil || ps.AverageAudienceScoreInCents() > winner.AverageAudienceScoreInCents() ||
+			(ps.AverageAudienceScoreInCents() == winner.AverageAudienceScoreInCents() && ps.UUID.String() < winner.UUID.String()) {
+			winner = ps
+		}
+	}
+
+	return winner
+}
+
+// Ranks players from the highest total score to the lowest.
+// Ties are broken by the higher average score, then by fewer interceptions received, then by UUID so that the order is deterministic.
+func (s *State) RankPlayers() []*PlayerState {
+	ranked := s.GetPlayerStates()
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+
+		if a.ScoreInCents != b.ScoreInCents {
+			return a.ScoreInCents > b.ScoreInCents
+		}
+
+		if a.AverageScoreInCents() != b.AverageScoreInCents() {
+			return a.AverageScoreInCents() > b.AverageScoreInCents()
+		}
+
+		if a.NumberOfInterceptionsReceived != b.NumberOfInterceptionsReceived {
+			return a.NumberOfInterceptionsReceived < b.NumberOfInterceptionsReceived
+		}
+
+		return a.UUID.String() < b.UUID.String()
+	})
+
+	return ranked
+}
+
+// Cumulative scores of the players in a lobby across the games they've played together.
+type SessionLeaderboard struct {
+	Entries map[uuid.UUID]*SessionEntry
+}
+
+// A player's cumulative score across the games in a session.
+type SessionEntry struct {
+	UUID         uuid.UUID
+	Name         string
+	ScoreInCents int
+	GamesPlayed  int
+}
+
+// Creates an empty session leaderboard.
+func CreateSessionLeaderboard() *SessionLeaderboard {
+	return &SessionLeaderboard{
+		Entries: make(map[uuid.UUID]*SessionEntry),
+	}
+}
+
+// Adds the scores of a finished game to the leaderboard.
+func (sl *SessionLeaderboard) AddGame(s *State) {
+	for _, ps := range s.PlayersToPlayerState {
+		se, ok := sl.Entries[ps.UUID]
+		if !ok {
+			se = &SessionEntry{UUID: ps.UUID}
+			sl.Entries[ps.UUID] = se
+		}
+
+		se.Name = ps.Name
+		se.ScoreInCents += ps.ScoreInCents
+		se.GamesPlayed++
+	}
+}
+
+// Ranks the session's players from the highest cumulative score to the lowest, ties are broken by UUID so that the order is deterministic.
+func (sl *SessionLeaderboard) Rank() []*SessionEntry {
+	ranked := make([]*SessionEntry, 0, len(sl.Entries))
+	for _, se := range sl.Entries {
+		ranked = append(ranked, se)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].ScoreInCents != ranked[j].ScoreInCents {
+			return ranked[i].ScoreInCents > ranked[j].ScoreInCents
+		}
+
+		return ranked[i].UUID.String() < ranked[j].UUID.String()
+	})
+
+	return ranked
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+// Number of cards every player needs for a round to be dealt from the jobs left over from earlier rounds,
+// so that each player still has a choice between two roles as well as a job card.
+const minimumCardsPerHand = 3
+
+// Checks if the game has any rounds left to play after the current one.
+func (s *State) HasRoundsLeft() bool {
+	return s.Round < s.TotalRounds
+}
+
+// Moves the game on to its next round, clearing every player's hand while their scores carry over.
+// New hands are dealt from the jobs that haven't been performed or applied for yet, if there are enough of them to go around.
+// Returns true if there aren't, in which case the job pool is emptied and players have to submit new jobs before cards are dealt.
+func (s *State) StartNextRound() bool {
+	s.Round++
+	s.ImprovSession = nil
+
+	players := s.GetPlayerOrder()
+	for _, uuid := range players {
+		s.PlayersToDealtJobs[uuid] = make([]*pack.Card, 0)
+
+		if ps, ok := s.PlayersToPlayerState[uuid]; ok {
+			ps.DrawnCards = nil
+			ps.JobCard = nil
+			ps.SelectedCard = nil
+		}
+	}
+
+	remaining := make([]*pack.Card, 0, len(s.JobPool))
+	for _, card := range s.JobPool {
+		if !s.retiredCards[card] {
+			remaining = append(remaining, card)
+		}
+	}
+
+	if len(remaining) >= len(players)*minimumCardsPerHand {
+		logger.Verbosef("[game] Dealing round %d from the %d jobs left in the pool.", s.Round, len(remaining))
+		s.JobPool = remaining
+		return false
+	}
+
+	// Players come up with N+1 jobs again, just like the first round
+	s.JobPool = make([]*pack.Card, 0)
+	s.JobInputsPerPlayer = len(players) + 1
+	for _, uuid := range players {
+		s.PlayersToSubmittedJobs[uuid] = make([]*pack.Card, 0)
+	}
+
+	logger.Verbosef("[game] Only %d jobs are left in the pool, players are submitting new jobs for round %d.", len(remaining), s.Round)
+
+	return true
+}
+
+// Gives a player their hand for the current round, creating their player state if this is their first round.
+// Players that already have a state keep their scores and interceptions from earlier rounds.
+func (s *State) DealHandToPlayer(uuid uuid.UUID, name string, drawnCards []*pack.Card, jobCard *pack.Card) {
+	ps, ok := s.PlayersToPlayerState[uuid]
+	if !ok {
+		s.CreatePlayerStateWithUUID(uuid, name, drawnCards, jobCard)
+		return
+	}
+
+	ps.Name = name
+	ps.DrawnCards = drawnCards
+	ps.JobCard = jobCard
+	ps.SelectedCard = nil
+}
+
+// Marks a card as used up for the rest of the game.
+func (s *State) retireCard(card *pack.Card) {
+	if card != nil {
+		s.retiredCards[card] = true
+	}
+}
@@ -3,7 +3,6 @@ package game
 import (
 	"math/rand"
 	"strings"
-	"time"
 
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
 	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
@@ -16,37 +15,62 @@ var Config *GameConfig = GetGameConfig()
 
 // Maintains the state of the game on the server.
 type State struct {
+	Phase                  Phase
 	ImprovSession          *ImprovSession
 	JobPool                []*pack.Card
 	JobInputsPerPlayer     int
 	PlayersToSubmittedJobs map[uuid.UUID][]*pack.Card
 	PlayersToDealtJobs     map[uuid.UUID][]*pack.Card
 	PlayersToPlayerState   map[uuid.UUID]*PlayerState
+	Judges                 map[uuid.UUID]bool
+	RoundHistory           []*RoundRecord
+	Round                  int
+	TotalRounds            int
+	rng                    *rand.Rand
+	playerOrder            []uuid.UUID
+	retiredCards           map[*pack.Card]bool
 }
 
 type PlayerState struct {
-	UUID                    uuid.UUID
-	DrawnCards              []*pack.Card
-	JobCard                 *pack.Card
-	SelectedCard            *pack.Card
-	ScoreInCents            int
-	NumberOfScoresSubmitted int
+	UUID                          uuid.UUID
+	Name                          string
+	DrawnCards                    []*pack.Card
+	JobCard                       *pack.Card
+	SelectedCard                  *pack.Card
+	ScoreInCents                  int
+	RawScoreInCents               int
+	NumberOfScoresSubmitted       int
+	NumberOfInterceptionsReceived int
+	NumberOfInterceptionsUsed     int
+	Interceptions                 []*InterceptionRecord
+	AudienceScoreInCents          int
+	NumberOfAudienceVotes         int
+	RetiredCards                  map[*pack.Card]bool
 }
 
 // Initializes the game state with the current number of players extracted from a list of their UUIDs.
-func CreateGameState(uuids []uuid.UUID) *State {
+// Every shuffle and deal in the game is drawn from the passed source, so a game with an identically seeded source, player order and inputs plays out identically.
+func CreateGameState(uuids []uuid.UUID, src rand.Source) *State {
 	numPlayers := len(uuids)
 
 	// Players are required to come up with N+1 jobs
 	numRequiredJobInputs := numPlayers + 1
 
 	s := &State{
+		Phase:                  LobbyPhase,
 		ImprovSession:          nil,
 		JobPool:                make([]*pack.Card, 0),
 		JobInputsPerPlayer:     numRequiredJobInputs,
 		PlayersToSubmittedJobs: make(map[uuid.UUID][]*pack.Card),
 		PlayersToDealtJobs:     make(map[uuid.UUID][]*pack.Card),
 		PlayersToPlayerState:   make(map[uuid.UUID]*PlayerState),
+		Judges:                 make(map[uuid.UUID]bool),
+		RoundHistory:           make([]*RoundRecord, 0),
+		Round:                  1,
+		TotalRounds:            Config.GetNumberOfRounds(),
+		rng:                    rand.New(src),
+		playerOrder:            append([]uuid.UUID(nil), uuids...),
+		retiredCards:           make(map[*pack.Card]bool),
 	}
 
 	// Construct the array of jobs for each connected UUID
@@ -61,48 +85,101 @@ func CreateGameState(uuids []uuid.UUID) *State {
 		if _, ok := s.PlayersToDealtJobs[uuid]; !ok {
 			s.PlayersToDealtJobs[uuid] = make([]*pack.Card, 0)
 		}
+
+		s.Judges[uuid] = true
 	}
 
 	return s
 }
 
 // Creates a player state with UUID and stores it inside the game state.
-func (s *State) CreatePlayerStateWithUUID(uuid uuid.UUID, drawnCards []*pack.Card, jobCard *pack.Card) {
+func (s *State) CreatePlayerStateWithUUID(uuid uuid.UUID, name string, drawnCards []*pack.Card, jobCard *pack.Card) {
 	ps := &PlayerState{
-		UUID:                    uuid,
-		DrawnCards:              drawnCards,
-		JobCard:                 jobCard,
-		SelectedCard:            nil,
-		ScoreInCents:            0,
-		NumberOfScoresSubmitted: 0,
+		UUID:                          uuid,
+		Name:                          name,
+		DrawnCards:                    drawnCards,
+		JobCard:                       jobCard,
+		SelectedCard:                  nil,
+		ScoreInCents:                  0,
+		RawScoreInCents:               0,
+		NumberOfScoresSubmitted:       0,
+		NumberOfInterceptionsReceived: 0,
+		AudienceScoreInCents:          0,
+		NumberOfAudienceVotes:         0,
+		RetiredCards:                  make(map[*pack.Card]bool),
 	}
 
 	s.PlayersToPlayerState[uuid] = ps
 }
 
+// Retrieves the card with the passed ID from the player's hand, returns nil if they didn't draw it.
+func (ps *PlayerState) FindDrawnCard(cardID uuid.UUID) *pack.Card {
+	for _, card := range ps.DrawnCards {
+		if card.CardID == cardID {
+			return card
+		}
+	}
+
+	return nil
+}
+
 // Resets the current game state.
 func (s *State) Reset() {
 	if s == nil {
 		return
 	}
 
+	s.Phase = LobbyPhase
 	s.ImprovSession = nil
 	s.JobPool = make([]*pack.Card, 0)
 	s.JobInputsPerPlayer = 0
 	s.PlayersToSubmittedJobs = make(map[uuid.UUID][]*pack.Card)
 	s.PlayersToDealtJobs = make(map[uuid.UUID][]*pack.Card)
 	s.PlayersToPlayerState = make(map[uuid.UUID]*PlayerState)
+	s.Judges = make(map[uuid.UUID]bool)
+	s.RoundHistory = make([]*RoundRecord, 0)
+	s.Round = 0
+	s.retiredCards = make(map[*pack.Card]bool)
+}
+
+// Checks if the user with the provided UUID is a player in the current game.
+func (s *State) IsPlayer(uuid uuid.UUID) bool {
+	_, ok := s.PlayersToSubmittedJobs[uuid]
+	return ok
 }
 
-// Converts the current job pool array to a string.
-func (s *State) JobPoolString() string {
-	valJobPool := make([]string, 0)
+// Checks if the user with the provided UUID can score improvs in the current game.
+func (s *State) IsJudge(uuid uuid.UUID) bool {
+	return s.Judges[uuid]
+}
 
-	for _, job := range s.JobPool {
-		valJobPool = append(valJobPool, *job.JobText)
+// Admits a user that joined mid-game as a judge, they become a full player in the next game.
+func (s *State) AddJudge(uuid uuid.UUID) {
+	s.Judges[uuid] = true
+}
+
+// Removes a user that left mid-game from the game, including the improv queue and score quorum.
+// Any score they already gave the current performer is withdrawn, so a kicked judge's vote doesn't count.
+// A player that's currently performing stays at the front of the queue so that their round can be ended.
+func (s *State) RemovePlayer(uuid uuid.UUID) {
+	delete(s.PlayersToSubmittedJobs, uuid)
+	delete(s.PlayersToDealtJobs, uuid)
+	delete(s.PlayersToPlayerState, uuid)
+	delete(s.Judges, uuid)
+
+	if s.ImprovSession != nil {
+		s.ImprovSession.WithdrawScore(uuid)
+
+		keepCurrent := s.Phase == ImprovPhase || s.Phase == ScoringPhase
+		s.ImprovSession.RemovePlayerFromQueue(uuid, keepCurrent)
 	}
+}
 
-	return "[ " + strings.Join(valJobPool, ", ") + " ]"
+// Removes an audience member that left mid-game, withdrawing any vote they already gave the current performer.
+func (s *State) RemoveAudienceMember(uuid uuid.UUID) {
+	if s.ImprovSession != nil {
+		s.ImprovSession.WithdrawAudienceVote(uuid)
+	}
 }
 
 // Prints a pretty format for the jobs submitted by each connected client.
@@ -124,19 +201,33 @@ func (s *State) JobUUIDMapToString(jobMap *map[uuid.UUID][]*pack.Card) string {
 	return out
 }
 
-// Returns a slice containing the player states currently on the server.
+// Returns a slice containing the player states currently on the server, in the order the players were passed to the game.
 func (s *State) GetPlayerStates() []*PlayerState {
-	keys := make([]*PlayerState, len(s.PlayersToPlayerState))
+	keys := make([]*PlayerState, 0, len(s.PlayersToPlayerState))
 
-	i := 0
-	for _, v := range s.PlayersToPlayerState {
-		keys[i] = v
-		i++
+	for _, uuid := range s.playerOrder {
+		if ps, ok := s.PlayersToPlayerState[uuid]; ok {
+			keys = append(keys, ps)
+		}
 	}
 
 	return keys
 }
 
+// Returns the UUIDs of the players still in the game, in the order they were passed to the game.
+// Iterating players in this order rather than over a map keeps the game reproducible from its seed.
+func (s *State) GetPlayerOrder() []uuid.UUID {
+	order := make([]uuid.UUID, 0, len(s.playerOrder))
+
+	for _, uuid := range s.playerOrder {
+		if s.IsPlayer(uuid) {
+			order = append(order, uuid)
+		}
+	}
+
+	return order
+}
+
 // Checks if the user with the provided UUID has finished submitting jobs.
 func (s *State) HasUserFinishedSubmittingJobs(uuid uuid.UUID) bool {
 	numJobsSubmitted := len(s.PlayersToSubmittedJobs[uuid])
@@ -171,25 +262,53 @@ func (s *State) CheckStartImprov() bool {
 		return false
 	}
 
-	s.ImprovSession = CreateImprovSession(s.GetPlayerStates())
+	// The cards players are performing with and applying for are used up, so later rounds don't deal them again
+	for _, ps := range s.PlayersToPlayerState {
+		s.retireCard(ps.SelectedCard)
+		s.retireCard(ps.JobCard)
+	}
+
+	s.ImprovSession = CreateImprovSession(s.GetPlayerStates(), s.rng)
 
 	return true
 }
 
-// Checks if all players have submitted a score for the last improv.
+// Checks if every judge, except the player that performed, has submitted a score for the last improv.
 func (s *State) HaveAllUsersSubmitedScoresForLastImprov() bool {
-	if s.ImprovSession.GetNumberOfPlayersLeftToImprov() < 0 {
+	performer := s.ImprovSession.GetCurrentImprovPlayer()
+	if performer == nil {
 		return false
 	}
 
-	ss := s.ImprovSession.GetNumberOfScoresSubmittedForCurrentPlayer()
-	exceptPlayer := len(s.PlayersToSubmittedJobs) - 1
+	for judge := range s.Judges {
+		if judge != performer.UUID && !s.ImprovSession.HasJudgeScored(judge) {
+			return false
+		}
+	}
 
-	return ss == exceptPlayer
+	return true
 }
 
 // Adds a job to the list of jobs for a user with the passed UUID.
-func (s *State) AddJob(targetUUID uuid.UUID, sj *string) {
+// Returns an error if the moderator rejects it or it duplicates a job that's already in the pool.
+func (s *State) AddJob(targetUUID uuid.UUID, sj *string) error {
+	if err := GetJobModerator().Review(*sj); err != nil {
+		logger.Verbosef("[game] Rejected job submitted by %s: %v", targetUUID.String(), err)
+		return err
+	}
+
+	if s.IsJobInPool(*sj) {
+		logger.Verbosef("[game] Rejected duplicate job submitted by %s.", targetUUID.String())
+		return pack.NewCodedError(pack.ErrorJobDuplicate, "Job submission request was received, but the same job has already been submitted.")
+	}
+
+	s.addJobCard(targetUUID, sj)
+
+	return nil
+}
+
+// Adds a job card for a user with the passed UUID without moderating it.
+func (s *State) addJobCard(targetUUID uuid.UUID, sj *string) {
 	// Early out if this user has already submitted their required jobs
 	if s.HasUserFinishedSubmittingJobs(targetUUID) {
 		return
@@ -214,31 +333,94 @@ func (s *State) AddJob(targetUUID uuid.UUID, sj *string) {
 	logger.Debugf("%s", s.JobUUIDMapToString(&s.PlayersToSubmittedJobs))
 }
 
-// Deals jobs to players.
-func (s *State) DealJobsToPlayers() {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	r.Shuffle(len(s.JobPool), func(i, j int) { s.JobPool[i], s.JobPool[j] = s.JobPool[j], s.JobPool[i] })
-	logger.Verbosef("Shuffled JobList: %s", s.JobPoolString())
-
-	// Each player gets drawn N cards (where N is the number of clients connected)
-	// Each player is assigned one job card that they are applying for
-	numPlayers := len(s.PlayersToSubmittedJobs)
-	size := (numPlayers + 1)
-	evenSplits := make([][]*pack.Card, 0)
-	var j int
-	for i := 0; i < len(s.JobPool); i += size {
-		j += size
-		if j > len(s.JobPool) {
-			j = len(s.JobPool)
+// Fills in the jobs of players that haven't finished submitting from the fallback jobs, returns the UUIDs of the players that were filled in.
+// Fallback jobs that are already in the pool are skipped until the fallback jobs run out.
+func (s *State) FillMissingJobs() []uuid.UUID {
+	filled := make([]uuid.UUID, 0)
+
+	deck := make([]string, 0)
+
+	for _, uuid := range s.GetPlayerOrder() {
+		if s.HasUserFinishedSubmittingJobs(uuid) {
+			continue
+		}
+
+		for !s.HasUserFinishedSubmittingJobs(uuid) {
+			if len(deck) == 0 {
+				deck = s.drawFallbackDeck()
+			}
+
+			job := deck[0]
+			deck = deck[1:]
+			s.addJobCard(uuid, &job)
+		}
+
+		filled = append(filled, uuid)
+	}
+
+	return filled
+}
+
+// Checks if a job matching the passed text is already in the pool, ignoring case and punctuation.
+func (s *State) IsJobInPool(job string) bool {
+	key := normalizeWords(job)
+	for _, card := range s.JobPool {
+		if normalizeWords(*card.JobText) == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Shuffles the fallback jobs into a deck, preferring jobs that aren't already in the pool.
+func (s *State) drawFallbackDeck() []string {
+	fallbackJobs := Config.GetFallbackJobs()
+	deck := make([]string, 0)
+	for _, job := range fallbackJobs {
+		if !s.IsJobInPool(job) {
+			deck = append(deck, job)
 		}
+	}
+
+	// Every fallback job has been used, so repeats are unavoidable
+	if len(deck) == 0 {
+		deck = append(deck, fallbackJobs...)
+	}
+
+	s.rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+
+	return deck
+}
+
+// Selects a random card from the hand of every player that hasn't selected one, returns the players whose card was selected.
+func (s *State) AutoSelectCards() []*PlayerState {
+	selected := make([]*PlayerState, 0)
 
-		evenSplits = append(evenSplits, s.JobPool[i:j])
+	for _, ps := range s.GetPlayerStates() {
+		if ps.SelectedCard != nil || len(ps.DrawnCards) == 0 {
+			continue
+		}
+
+		ps.SelectedCard = ps.DrawnCards[s.rng.Intn(len(ps.DrawnCards))]
+		selected = append(selected, ps)
+	}
+
+	return selected
+}
+
+// Deals jobs to players in balanced hands, nobody is dealt a job they submitted unless it can't be avoided.
+func (s *State) DealJobsToPlayers() {
+	authors := make(map[*pack.Card]uuid.UUID)
+	for author, cards := range s.PlayersToSubmittedJobs {
+		for _, card := range cards {
+			authors[card] = author
+		}
 	}
 
-	i := 0
-	for uuid := range s.PlayersToDealtJobs {
-		s.PlayersToDealtJobs[uuid] = evenSplits[i]
-		i++
+	// Each player is drawn a balanced share of the pool, the last of which is the job card they're applying for
+	for uuid, hand := range dealHands(s.rng, s.GetPlayerOrder(), s.JobPool, authors) {
+		s.PlayersToDealtJobs[uuid] = hand
 	}
 
 	logger.Debugf("%s", s.JobUUIDMapToString(&s.PlayersToDealtJobs))
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
+)
+
+func TestGameStateIsReproducibleFromItsSource(t *testing.T) {
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	deal := func(seed int64) []string {
+		s := CreateGameState(players, rand.NewSource(seed))
+		for i, player := range players {
+			for j := 0; j < s.JobInputsPerPlayer; j++ {
+				job := fmt.Sprintf("Job %d %d", i, j)
+				if err := s.AddJob(player, &job); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		s.DealJobsToPlayers()
+
+		hands := make([]string, 0)
+		for _, player := range players {
+			for _, card := range s.PlayersToDealtJobs[player] {
+				hands = append(hands, *card.JobText)
+			}
+		}
+
+		return hands
+	}
+
+	first, second := deal(42), deal(42)
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Fatalf("games with the same seed dealt different hands:\n%v\n%v", first, second)
+	}
+}
+
+func TestRemovedVotersAreExcludedFromScoring(t *testing.T) {
+	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	audience := []uuid.UUID{uuid.New(), uuid.New()}
+
+	s := CreateGameState(players, rand.NewSource(1))
+	for _, player := range players {
+		s.CreatePlayerStateWithUUID(player, player.String(), nil, nil)
+	}
+	s.ImprovSession = CreateImprovSession(s.GetPlayerStates(), s.rng)
+	s.Phase = ScoringPhase
+
+	performer := s.ImprovSession.GetCurrentImprovPlayer()
+	judges := make([]uuid.UUID, 0)
+	for _, player := range players {
+		if player != performer.UUID {
+			judges = append(judges, player)
+		}
+	}
+
+	for i, judge := range judges {
+		if _, err := s.ImprovSession.SubmitScoreForPlayer(judge, &pack.ScoreSubmissionMessage{ScoreInCents: 100 + 900*i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, voter := range audience {
+		s.ImprovSession.SubmitAudienceVoteForPlayer(voter, &pack.ScoreSubmissionMessage{ScoreInCents: 100 + 900*i})
+	}
+
+	// The judge and audience member that gave the high scores are kicked before scoring finishes
+	s.RemovePlayer(judges[1])
+	s.RemoveAudienceMember(audience[1])
+
+	if !s.HaveAllUsersSubmitedScoresForLastImprov() {
+		t.Fatal("the remaining judge has scored, but the quorum isn't met")
+	}
+	if got := s.ImprovSession.ApplyScoresForPlayer(&SumScoreAggregator{}); got != 100 {
+		t.Errorf("performance scored %d, want 100", got)
+	}
+	if performer.RawScoreInCents != 100 || performer.NumberOfScoresSubmitted != 1 {
+		t.Errorf("performer has %d raw cents from %d scores, want 100 from 1", performer.RawScoreInCents, performer.NumberOfScoresSubmitted)
+	}
+	if performer.AudienceScoreInCents != 100 || performer.NumberOfAudienceVotes != 1 {
+		t.Errorf("performer has %d audience cents from %d votes, want 100 from 1", performer.AudienceScoreInCents, performer.NumberOfAudienceVotes)
+	}
+}
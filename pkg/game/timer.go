@@ -0,0 +1,179 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// A timer that invokes a callback once it expires and can be paused, resumed or reset in the meantime.
+type PausableTimer struct {
+	timer      *time.Timer
+	deadline   time.Time
+	remaining  time.Duration
+	paused     bool
+	stopped    bool
+	generation int
+	callback   func()
+	mutex      sync.Mutex
+}
+
+// Creates and starts a pausable timer that invokes the callback after the passed duration.
+func StartPausableTimer(d time.Duration, cb func()) *PausableTimer {
+	t := &PausableTimer{
+		callback: cb,
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.arm(d)
+
+	return t
+}
+
+// Arms the underlying timer, any previously armed callbacks are invalidated. Requires the mutex to be held.
+func (t *PausableTimer) arm(d time.Duration) {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+
+	t.generation++
+	generation := t.generation
+
+	t.deadline = time.Now().Add(d)
+	t.timer = time.AfterFunc(d, func() {
+		t.mutex.Lock()
+		if t.generation != generation || t.paused || t.stopped {
+			t.mutex.Unlock()
+			return
+		}
+		t.stopped = true
+		t.mutex.Unlock()
+
+		t.callback()
+	})
+}
+
+// Pauses the timer, retaining the time remaining. Returns false if the timer is already paused or has finished.
+func (t *PausableTimer) Pause() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.paused || t.stopped {
+		return false
+	}
+
+	t.timer.Stop()
+	t.generation++
+	t.remaining = time.Until(t.deadline)
+	t.paused = true
+
+	return true
+}
+
+// Resumes a paused timer with the time that was remaining when it was paused.
+func (t *PausableTimer) Resume() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.paused || t.stopped {
+		return false
+	}
+
+	t.paused = false
+	t.arm(t.remaining)
+
+	return true
+}
+
+// Resets the timer to expire after the passed duration, a paused timer stays paused with the new duration remaining.
+func (t *PausableTimer) Reset(d time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopped {
+		return
+	}
+
+	if t.paused {
+		t.remaining = d
+		return
+	}
+
+	t.arm(d)
+}
+
+// Stops the timer without invoking its callback.
+func (t *PausableTimer) Stop() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stopped = true
+	t.generation++
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// Retrieves the time remaining before the timer expires.
+func (t *PausableTimer) Remaining() time.Duration {
+	if t == nil {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stopped {
+		return 0
+	}
+
+	if t.paused {
+		return t.remaining
+	}
+
+	if remaining := time.Until(t.deadline); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// Checks if the timer has yet to expire or be stopped, paused timers are still considered running.
+func (t *PausableTimer) IsRunning() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return !t.stopped
+}
+
+// Checks if the timer is currently paused.
+func (t *PausableTimer) IsPaused() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.paused
+}
@@ -1,10 +1,11 @@
 package network
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
-	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
@@ -14,19 +15,31 @@ type ClientType int
 const (
 	Web ClientType = iota
 	Game
+	Audience
 )
 
 const (
-	alivePingTimeoutSeconds = 45 * time.Second
+	// Time between ping control frames, kept below the idle timeout of services such as Heroku
+	pingPeriodSeconds = 30 * time.Second
+	// Time allowed to read the next message or pong from a peer before it's considered dead
+	pongWaitSeconds = 60 * time.Second
+	// Number of outbound messages that can be queued for a client before it's treated as a slow consumer
+	clientSendBufferSize = 64
+	// Time allowed to write a single message to a socket
+	writeWaitSeconds = 10 * time.Second
 )
 
 type Client struct {
-	clientType ClientType
-	UUID       uuid.UUID
-	Name       string
-	lobby      *Lobby
-	conn       *websocket.Conn
-	pingTimer  *time.Timer
+	clientType  ClientType
+	UUID        uuid.UUID
+	Name        string
+	lobby       *Lobby
+	conn        *websocket.Conn
+	send        chan []byte
+	resumeToken string
+	graceTimer  *time.Timer
+	latency     time.Duration
+	remoteIP    string
 }
 
 // Creates a game client associated with a particular lobby and connection
@@ -40,31 +53,123 @@ func CreateClient(l *Lobby, c *websocket.Conn, clientType ClientType) *Client {
 		clientType: clientType,
 		UUID:       uuid,
 		lobby:      l,
-		conn:       c,
 	}
+	cl.attach(c)
 
-	// Start a goroutine that pumps ping messages to this client.
-	// This ensures the websocket connection stays alive, as, some services
-	// such as Heroku will auto disconnect sockets if they remain idle.
-	go cl.startAlivePingPump()
+	// Clients are issued a token that lets them resume their session if their socket drops,
+	// for game clients this doubles as the host secret
+	rt, err := utils.GenerateToken()
+	if err != nil {
+		logger.Errorf("Failed to generate resume token: %v", err)
+	}
+	cl.resumeToken = rt
 
 	return cl
 }
 
-// Closes a client and it's corresponding websocket connection.
-func (c *Client) CloseClient() {
-	if c != nil {
+// Binds the client to a socket, starting a writer goroutine that drains a fresh outbound queue onto it.
+// The lobby's mutex must be held when calling this.
+func (c *Client) attach(conn *websocket.Conn) {
+	c.conn = conn
+	c.send = make(chan []byte, clientSendBufferSize)
+
+	go c.writePump(conn, c.send)
+}
+
+// Unbinds the client from its socket, the writer goroutine closes the socket once its queue is drained.
+// The lobby's mutex must be held when calling this.
+func (c *Client) detach() {
+	if c.send != nil {
+		close(c.send)
+	}
+
+	c.conn = nil
+	c.send = nil
+}
+
+// Queues a message to be written to the client's socket, dropped if the client is disconnected.
+// Clients that can't keep up with their queue are disconnected as slow consumers.
+// The lobby's mutex must be held when calling this.
+func (c *Client) Send(msg []byte) bool {
+	if !c.IsConnected() {
+		return false
+	}
+
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		logger.Warnf("[server] Outbound queue for client %s overflowed, disconnecting it as a slow consumer.", c.UUID.String())
+
+		// Closing the socket fails the read loop, which feeds this client through the normal disconnect path
 		c.conn.Close()
+		return false
 	}
 }
 
-// Creates a pump that broadcasts a ping to all clients in the lobby in a fixed duration.
-func (c *Client) startAlivePingPump() {
+// Writes every message queued for a socket, this is the only goroutine that writes to the socket.
+// Ping control frames are also pumped from here, this keeps the socket alive, as, some services
+// such as Heroku will auto disconnect sockets if they remain idle, and lets dead peers be detected.
+func (c *Client) writePump(conn *websocket.Conn, send <-chan []byte) {
+	ticker := time.NewTicker(pingPeriodSeconds)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
 	for {
-		c.pingTimer = time.NewTimer(alivePingTimeoutSeconds)
-		<-c.pingTimer.C
+		select {
+		case msg, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(writeWaitSeconds))
+
+			// The queue was closed, so let the peer know the socket is going away
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
 
-		aliveData := pack.MarshalBasicMessage(pack.Alive)
-		c.lobby.dmSocket <- CreateSocketDMRequest(c.conn, aliveData)
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				logger.Verbosef("[server] Failed to write to client %s: %v", c.UUID.String(), err)
+				return
+			}
+		case <-ticker.C:
+			// The ping carries the time it was sent so that the pong can be used to measure latency
+			sentAt := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+			if err := conn.WriteControl(websocket.PingMessage, sentAt, time.Now().Add(writeWaitSeconds)); err != nil {
+				logger.Verbosef("[server] Failed to ping client %s: %v", c.UUID.String(), err)
+				return
+			}
+		}
 	}
 }
+
+// Measures the round-trip latency of a ping from the payload echoed back in its pong.
+func measurePongLatency(appData string) (time.Duration, bool) {
+	sentAt, err := strconv.ParseInt(appData, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Since(time.Unix(0, sentAt)), true
+}
+
+// Closes a client and it's corresponding websocket connection.
+// The lobby's mutex must be held when calling this.
+func (c *Client) CloseClient() {
+	if c != nil && c.conn != nil {
+		c.detach()
+	}
+}
+
+// Stops the client's reconnect grace timer, if it's running.
+func (c *Client) stopGraceTimer() {
+	if c != nil && c.graceTimer != nil {
+		c.graceTimer.Stop()
+		c.graceTimer = nil
+	}
+}
+
+// Checks if the client currently has a live websocket connection.
+func (c *Client) IsConnected() bool {
+	return c != nil && c.conn != nil
+}
@@ -1,96 +1,118 @@
 package network
 
 import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils"
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils/json"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/game"
 	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// A lobby and its game state, all fields are guarded by the lobby's mutex.
+// Handlers, timer callbacks and disconnects from any goroutine must hold the mutex while touching the lobby.
 type Lobby struct {
-	hostGameClient   *Client
-	webClients       map[*Client]bool
-	socketsToClients map[*websocket.Conn]*Client
-	lobbyCode        string
-	register         chan *Client
-	broadcast        chan []byte
-	unicastGame      chan []byte
-	unicastWeb       chan []byte
-	dmSocket         chan *SocketDMRequest
-	disconnect       chan *websocket.Conn
-}
-
-type SocketDMRequest struct {
-	DestSocket *websocket.Conn
-	Data       []byte
+	hostGameClient        *Client
+	webClients            map[*Client]bool
+	audienceClients       map[*Client]bool
+	socketsToClients      map[*websocket.Conn]*Client
+	resumeTokensToClients map[string]*Client
+	bannedResumeTokens    map[string]bool
+	bannedIPs             map[string]bool
+	lobbyCode             string
+	gameState             *game.State
+	leaderboard           *game.SessionLeaderboard
+	scoreAggregator       game.ScoreAggregator
+	seed                  int64
+	hostMissing           bool
+	hostPaused            bool
+	intermissionTimer     *game.PausableTimer
+	phaseTimer            *game.PausableTimer
+	onClose               func(*Lobby)
+	onLeave               func(*Lobby, *Client)
+	closed                bool
+	mutex                 sync.Mutex
 }
 
-func CreateLobby() *Lobby {
+// Creates a lobby identified by the passed lobby code, each lobby maintains its own game state.
+// The onClose callback is invoked once the lobby closes itself, e.g., when the host never reconnects,
+// and the onLeave callback is invoked with the lobby's mutex held when a web client leaves for good.
+func CreateLobby(lobbyCode string, onClose func(*Lobby), onLeave func(*Lobby, *Client)) *Lobby {
 	return &Lobby{
-		hostGameClient:   nil,
-		webClients:       make(map[*Client]bool),
-		socketsToClients: make(map[*websocket.Conn]*Client),
-		lobbyCode:        "1234", // todo
-		register:         make(chan *Client),
-		broadcast:        make(chan []byte),
-		unicastGame:      make(chan []byte),
-		unicastWeb:       make(chan []byte),
-		dmSocket:         make(chan *SocketDMRequest),
-		disconnect:       make(chan *websocket.Conn),
+		hostGameClient:        nil,
+		webClients:            make(map[*Client]bool),
+		audienceClients:       make(map[*Client]bool),
+		socketsToClients:      make(map[*websocket.Conn]*Client),
+		resumeTokensToClients: make(map[string]*Client),
+		bannedResumeTokens:    make(map[string]bool),
+		bannedIPs:             make(map[string]bool),
+		lobbyCode:             lobbyCode,
+		gameState:             nil,
+		leaderboard:           game.CreateSessionLeaderboard(),
+		scoreAggregator:       game.Config.GetScoreAggregator(),
+		seed:                  0,
+		hostMissing:           false,
+		hostPaused:            false,
+		intermissionTimer:     nil,
+		phaseTimer:            nil,
+		onClose:               onClose,
+		onLeave:               onLeave,
+		closed:                false,
 	}
 }
 
-func CreateSocketDMRequest(c *websocket.Conn, data []byte) *SocketDMRequest {
-	return &SocketDMRequest{
-		DestSocket: c,
-		Data:       data,
+// Resolves the seed for the lobby's next game.
+// A seed requested when the lobby was created takes precedence over the seed passed with the -seed flag, then the configured seed,
+// and a fresh seed is picked if none are set.
+func (l *Lobby) resolveGameSeed() int64 {
+	if l.seed != 0 {
+		return l.seed
+	}
+
+	if seed := utils.GetSeedFlag(); seed != 0 {
+		return seed
+	}
+
+	if game.Config.Random.Seed != 0 {
+		return game.Config.Random.Seed
 	}
+
+	return time.Now().UnixNano()
 }
 
-// Closes the lobby, closing each connected client and the lobby's communication channels.
+// Closes the lobby, closing each connected client and stopping the lobby's timers.
 func (l *Lobby) closeLobby() {
-	if l == nil {
+	if l == nil || l.closed {
 		return
 	}
 
-	logger.Verbose("[server] Closing lobby.")
+	logger.Verbosef("[server] Closing lobby %s.", l.lobbyCode)
 
+	l.closed = true
+
+	l.hostGameClient.stopGraceTimer()
 	l.hostGameClient.CloseClient()
 	for c := range l.webClients {
+		c.stopGraceTimer()
+		c.CloseClient()
+	}
+	for c := range l.audienceClients {
+		c.stopGraceTimer()
 		c.CloseClient()
 	}
 
-	close(l.register)
-	close(l.broadcast)
-	close(l.unicastGame)
-	close(l.unicastWeb)
-	close(l.dmSocket)
-	close(l.disconnect)
-}
-
-// Standard execution of the lobby, goroutine safe.
-func (l *Lobby) run() {
-	for {
-		select {
-		// Triggered when a client is registered on the server
-		case c := <-l.register:
-			l.registerClient(c)
-		// Triggered when a message is broadcasted to all clients
-		case msg := <-l.broadcast:
-			l.broadcastToClients(msg)
-		// Triggered when a message is unicasted to the game client
-		case msg := <-l.unicastGame:
-			l.unicastToGameClient(msg)
-		// Triggered when a message is unicasted to all web clients
-		// (really just a broadcast without the hosting client, but it works)
-		case msg := <-l.unicastWeb:
-			l.unicastToWebClients(msg)
-		// Triggered when a message needs to be sent to a particular client
-		case sdr := <-l.dmSocket:
-			l.dmTargetSocket(sdr)
-		// Triggered when a client forcefully disconnects from the server, used to end the goroutine
-		case <-l.disconnect:
-			return
-		}
+	l.intermissionTimer.Stop()
+	l.phaseTimer.Stop()
+	if l.gameState != nil && l.gameState.ImprovSession != nil {
+		l.gameState.ImprovSession.SessionTimer.Stop()
 	}
 }
 
@@ -99,60 +121,668 @@ func (l *Lobby) registerClient(c *Client) {
 	// Map the socket to this client for reverse-lookup later
 	l.socketsToClients[c.conn] = c
 
+	// Map the resume token to this client so that it can reclaim its session later
+	l.resumeTokensToClients[c.resumeToken] = c
+
 	if c.clientType == Game {
 		l.hostGameClient = c
 		l.registerGameClient(c)
 	} else if c.clientType == Web {
 		l.webClients[c] = true
 		l.registerWebClient(c)
+	} else if c.clientType == Audience {
+		l.audienceClients[c] = true
+		l.registerAudienceClient(c)
 	} else {
 		panic("Unknown client type")
 	}
 }
 
-// Registers a game client on the server and responds with the lobby code.
+// Registers a game client on the server and responds with the lobby code and host secret.
 func (l *Lobby) registerGameClient(c *Client) {
 	logger.Verbose("[server] Registered a new Game client.")
 
-	lcm := pack.CreateLobbyCodeMessage(&l.lobbyCode)
+	lcm := pack.CreateHostLobbyCodeMessage(&l.lobbyCode, c.resumeToken)
 
 	// Respond with the lobby code to the game client
-	c.conn.WriteJSON(lcm)
+	c.Send(json.MarshalJSONBytes[pack.HostLobbyCodeMessage](lcm))
 }
 
-// Registers a web client and responds with the player's server ID.
+// Registers a web client and responds with the player's server ID and resume token.
 func (l *Lobby) registerWebClient(c *Client) {
 	logger.Verbose("[server] Registered a new Web client.")
 
-	pidm := pack.CreatePlayerIDMessage(pack.PlayerID, &c.UUID)
+	psm := pack.CreatePlayerSessionMessage(&c.UUID, c.resumeToken)
 
 	// Respond with the player ID to the web client.
-	c.conn.WriteJSON(pidm)
+	c.Send(json.MarshalJSONBytes[pack.PlayerSessionMessage](psm))
+}
+
+// Registers an audience client and responds with its server ID and resume token.
+func (l *Lobby) registerAudienceClient(c *Client) {
+	logger.Verbose("[server] Registered a new Audience client.")
+
+	psm := pack.CreatePlayerSessionMessage(&c.UUID, c.resumeToken)
+	psm.Audience = true
+
+	// Audience members resume their session the same way as players
+	c.Send(json.MarshalJSONBytes[pack.PlayerSessionMessage](psm))
+}
+
+// Detaches a client from its dropped socket and gives it a grace window to rejoin.
+// If the client is the host, the lobby is paused until the host reconnects.
+func (l *Lobby) suspendClient(conn *websocket.Conn) {
+	// The socket may have already been taken over by a rejoin
+	c, ok := l.socketsToClients[conn]
+	if !ok || l.closed {
+		return
+	}
+
+	logger.Verbosef("[server] Suspending client %s until it rejoins.", c.UUID.String())
+
+	delete(l.socketsToClients, c.conn)
+	c.CloseClient()
+
+	gracePeriod := game.Config.GetTypedReconnectGracePeriodSeconds()
+	if c.clientType == Game {
+		gracePeriod = game.Config.GetTypedHostReconnectGracePeriodSeconds()
+		l.enterHostMissing()
+	}
+
+	c.graceTimer = time.AfterFunc(gracePeriod, func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		l.expireClient(c)
+	})
+}
+
+// Removes a suspended client from the lobby if it hasn't rejoined within its grace window.
+// If the client is the host, the lobby is closed.
+func (l *Lobby) expireClient(c *Client) {
+	if c.IsConnected() || l.closed {
+		return
+	}
+
+	if c.clientType == Game {
+		logger.Verbosef("[server] Game client didn't reclaim lobby %s in time, closing it.", l.lobbyCode)
+
+		if l.onClose != nil {
+			l.onClose(l)
+		}
+		l.closeLobby()
+		l.gameState.Reset()
+
+		return
+	}
+
+	logger.Verbosef("[server] Client %s didn't rejoin in time, removing it from the lobby.", c.UUID.String())
+
+	l.removeClient(c)
+}
+
+// Removes a web or audience client from the lobby, closing its socket. Players are also removed from the running game.
+func (l *Lobby) removeClient(c *Client) {
+	c.stopGraceTimer()
+
+	if c.IsConnected() {
+		delete(l.socketsToClients, c.conn)
+		c.CloseClient()
+	}
+
+	delete(l.resumeTokensToClients, c.resumeToken)
+
+	if c.clientType == Audience {
+		delete(l.audienceClients, c)
+
+		if l.gameState != nil {
+			l.gameState.RemoveAudienceMember(c.UUID)
+		}
+
+		l.unicastToGameClient(pack.MarshalAudienceUpdateMessage(pack.AudienceLeft, &c.UUID, &c.Name, len(l.audienceClients)))
+
+		return
+	}
+
+	delete(l.webClients, c)
+
+	if l.onLeave != nil {
+		l.onLeave(l, c)
+	}
+}
+
+// Kicks a web or audience client from the lobby, banning its resume token and remote IP from returning if requested.
+func (l *Lobby) kickClient(c *Client, ban bool) {
+	if ban {
+		l.bannedResumeTokens[c.resumeToken] = true
+		if c.remoteIP != "" {
+			l.bannedIPs[c.remoteIP] = true
+		}
+	}
+
+	// The message is flushed before the socket closes, since closing the client drains its queue
+	c.Send(pack.MarshalKickedMessage(ban))
+
+	l.removeClient(c)
+}
+
+// Checks if a resume token or remote IP has been banned from the lobby.
+func (l *Lobby) isBanned(rt string, ip string) bool {
+	return l.bannedResumeTokens[rt] || (ip != "" && l.bannedIPs[ip])
+}
+
+// Re-binds the client of a given type owning the requested resume token to a new socket and replays its current state.
+// Returns nil if the lobby has closed or the token is unknown.
+func (l *Lobby) rejoinClient(conn *websocket.Conn, ct ClientType, rt string, ip string) *Client {
+	c, ok := l.resumeTokensToClients[rt]
+	if !ok || l.closed {
+		return nil
+	}
+
+	// Audience members resume their session the same way as players
+	if c.clientType != ct && !(ct == Web && c.clientType == Audience) {
+		return nil
+	}
+
+	c.stopGraceTimer()
+
+	// The previous socket may not have been noticed as dropped yet, so take it over
+	if c.IsConnected() {
+		delete(l.socketsToClients, c.conn)
+		c.CloseClient()
+	}
+
+	c.attach(conn)
+	c.remoteIP = ip
+	l.socketsToClients[c.conn] = c
+
+	if c.clientType == Game {
+		logger.Verbosef("[server] Game client reclaimed lobby %s.", l.lobbyCode)
+
+		l.leaveHostMissing()
+		c.Send(l.marshalSnapshot())
+
+		return c
+	}
+
+	logger.Verbosef("[server] Web client %s rejoined the lobby.", c.UUID.String())
+
+	psm := pack.CreatePlayerSessionMessage(&c.UUID, c.resumeToken)
+	psm.Audience = c.clientType == Audience
+	c.Send(json.MarshalJSONBytes[pack.PlayerSessionMessage](psm))
+
+	l.replaySessionState(c)
+
+	return c
+}
+
+// Pauses the lobby's timers and tells web clients that the host is reconnecting.
+func (l *Lobby) enterHostMissing() {
+	if l.hostMissing {
+		return
+	}
+
+	l.hostMissing = true
+	l.pauseTimers()
+
+	l.unicastToWebClients(pack.MarshalBasicMessage(pack.HostDisconnected))
+	l.unicastToAudience(pack.MarshalBasicMessage(pack.HostDisconnected))
+}
+
+// Resumes the lobby's timers and tells web clients that the host has returned.
+func (l *Lobby) leaveHostMissing() {
+	if !l.hostMissing {
+		return
+	}
+
+	l.hostMissing = false
+
+	// Timers stay held if the host paused the game before disconnecting
+	if !l.hostPaused {
+		l.resumeTimers()
+	}
+
+	l.unicastToWebClients(pack.MarshalBasicMessage(pack.HostReconnected))
+	l.unicastToAudience(pack.MarshalBasicMessage(pack.HostReconnected))
+
+	if l.phaseTimer.IsRunning() {
+		l.broadcastToClients(l.marshalPhaseCountdown())
+	}
+}
+
+// Checks if the lobby's timers are held, either because the host paused the game or because the host is reconnecting.
+func (l *Lobby) timersHeld() bool {
+	return l.hostMissing || l.hostPaused
+}
+
+// Retrieves the time left on the timer that's running in the current phase.
+func (l *Lobby) currentTimeRemaining() time.Duration {
+	switch l.currentPhase() {
+	case game.ImprovPhase:
+		return l.gameState.ImprovSession.GetSessionTimeRemaining()
+	case game.IntermissionPhase:
+		return l.intermissionTimer.Remaining()
+	default:
+		return l.phaseTimer.Remaining()
+	}
+}
+
+// Pauses the improv, intermission and phase timers, if they're running.
+func (l *Lobby) pauseTimers() {
+	l.intermissionTimer.Pause()
+	l.phaseTimer.Pause()
+	if l.gameState != nil && l.gameState.ImprovSession != nil {
+		l.gameState.ImprovSession.PauseSessionTimer()
+	}
+}
+
+// Resumes the improv, intermission and phase timers, if they're paused.
+func (l *Lobby) resumeTimers() {
+	l.intermissionTimer.Resume()
+	l.phaseTimer.Resume()
+	if l.gameState != nil && l.gameState.ImprovSession != nil {
+		l.gameState.ImprovSession.ResumeSessionTimer()
+	}
+}
+
+// Builds and marshals a snapshot of the lobby and its game state for the host.
+func (l *Lobby) marshalSnapshot() []byte {
+	lsm := &pack.LobbySnapshotMessage{
+		LobbyCode:    l.lobbyCode,
+		Phase:        l.currentPhase().String(),
+		Players:      make([]*pack.PlayerSnapshot, 0),
+		ImprovQueue:  make([]uuid.UUID, 0),
+		AudienceSize: len(l.audienceClients),
+	}
+
+	gs := l.gameState
+	if gs != nil {
+		lsm.GameStarted = true
+		lsm.NumberOfJobs = gs.JobInputsPerPlayer
+
+		if gs.ImprovSession != nil {
+			for _, ps := range gs.ImprovSession.PlayerQueue {
+				lsm.ImprovQueue = append(lsm.ImprovQueue, ps.UUID)
+			}
+
+			// During intermission the front of the queue is the next player, who hasn't started performing yet
+			if ips := gs.ImprovSession.GetCurrentImprovPlayer(); ips != nil && isPerformancePhase(gs.Phase) {
+				lsm.CurrentImprovPlayerID = &ips.UUID
+			}
+
+			lsm.TimeRemainingInSeconds = int(l.currentTimeRemaining().Seconds())
+		}
+
+		if l.phaseTimer.IsRunning() {
+			lsm.TimeRemainingInSeconds = int(l.phaseTimer.Remaining().Seconds())
+		}
+
+		lsm.Paused = l.hostPaused
+	}
+
+	for c := range l.webClients {
+		psn := &pack.PlayerSnapshot{
+			Player:                *pack.CreatePlayer(&c.UUID, &c.Name),
+			Connected:             c.IsConnected(),
+			LatencyInMilliseconds: int(c.latency.Milliseconds()),
+		}
+
+		if gs != nil {
+			psn.FinishedSubmittingJobs = gs.HasUserFinishedSubmittingJobs(c.UUID)
+
+			if ps, ok := gs.PlayersToPlayerState[c.UUID]; ok {
+				psn.JobCard = ps.JobCard
+				psn.SelectedCard = ps.SelectedCard
+				psn.ScoreInCents = ps.ScoreInCents
+			}
+		}
+
+		lsm.Players = append(lsm.Players, psn)
+	}
+
+	return pack.MarshalLobbySnapshotMessage(lsm)
+}
+
+// Sends a rejoining web client the messages needed to restore the current phase of the game.
+func (l *Lobby) replaySessionState(c *Client) {
+	if l.hostMissing {
+		c.Send(pack.MarshalBasicMessage(pack.HostDisconnected))
+	}
+
+	if l.hostPaused {
+		c.Send(pack.MarshalPauseStateMessage(pack.GamePaused, int(math.Ceil(l.currentTimeRemaining().Seconds()))))
+	}
+
+	gs := l.gameState
+	if gs == nil {
+		return
+	}
+
+	ps, hasHand := gs.PlayersToPlayerState[c.UUID]
+
+	switch gs.Phase {
+	case game.JobSubmissionPhase:
+		if _, isPlayer := gs.PlayersToSubmittedJobs[c.UUID]; isPlayer && !gs.HasUserFinishedSubmittingJobs(c.UUID) {
+			if gs.Round > 1 {
+				c.Send(pack.MarshalRoundStartMessage(gs.Round, gs.TotalRounds, gs.JobInputsPerPlayer))
+			} else {
+				gsm := pack.CreateGameStartMessage(gs.JobInputsPerPlayer)
+				c.Send(json.MarshalJSONBytes[pack.GameStartMessage](gsm))
+			}
+		}
+	case game.CardSelectionPhase:
+		if hasHand && ps.SelectedCard == nil {
+			c.Send(pack.MarshalReceivedCardsMessage(ps.DrawnCards, ps.JobCard, gs.Round, gs.TotalRounds))
+		}
+	case game.ImprovPhase, game.ScoringPhase, game.IntermissionPhase:
+		// Hands are replayed during improv so that interceptions can be played
+		if hasHand {
+			c.Send(pack.MarshalReceivedCardsMessage(ps.DrawnCards, ps.JobCard, gs.Round, gs.TotalRounds))
+		}
+
+		// During intermission the front of the queue is the next player, who's announced once they start performing
+		if gs.Phase == game.IntermissionPhase {
+			remaining := int(math.Ceil(l.intermissionTimer.Remaining().Seconds()))
+			c.Send(pack.MarshalPhaseCountdownMessage(gs.Phase.String(), remaining, l.intermissionTimer.IsPaused()))
+			break
+		}
+
+		if ips := gs.ImprovSession.GetCurrentImprovPlayer(); ips != nil {
+			c.Send(pack.MarshalPlayerIDMessage(pack.PlayerID, &ips.UUID))
+		}
+
+		if gs.Phase == game.ScoringPhase {
+			c.Send(pack.MarshalBasicMessage(pack.TimerFinished))
+		}
+	case game.FinishedPhase:
+		c.Send(l.marshalGameResults())
+	}
+
+	if l.phaseTimer.IsRunning() {
+		c.Send(l.marshalPhaseCountdown())
+	}
+}
+
+// Checks if a player is performing or being scored in the passed phase.
+func isPerformancePhase(p game.Phase) bool {
+	return p == game.ImprovPhase || p == game.ScoringPhase
+}
+
+// Builds and marshals the results of the lobby's finished game.
+func (l *Lobby) marshalGameResults() []byte {
+	gs := l.gameState
+
+	rankings := make([]*pack.PlayerResult, 0)
+	for i, ps := range gs.RankPlayers() {
+		name := ps.Name
+		rankings = append(rankings, &pack.PlayerResult{
+			Player:                        *pack.CreatePlayer(&ps.UUID, &name),
+			Rank:                          i + 1,
+			JobCard:                       ps.JobCard,
+			SelectedCard:                  ps.SelectedCard,
+			ScoreInCents:                  ps.ScoreInCents,
+			AverageScoreInCents:           ps.AverageScoreInCents(),
+			NumberOfScores:                ps.NumberOfScoresSubmitted,
+			NumberOfInterceptionsReceived: ps.NumberOfInterceptionsReceived,
+			AudienceScoreInCents:          ps.AudienceScoreInCents,
+			NumberOfAudienceVotes:         ps.NumberOfAudienceVotes,
+		})
+	}
+
+	rounds := make([]*pack.RoundResult, 0)
+	for i, rr := range gs.RoundHistory {
+		interceptedBy := make([]*pack.InterceptionResult, 0, len(rr.Interceptions))
+		for _, ir := range rr.Interceptions {
+			name := ir.InterceptorName
+			interceptedBy = append(interceptedBy, &pack.InterceptionResult{
+				Player: *pack.CreatePlayer(&ir.InterceptorUUID, &name),
+				Card:   ir.Card,
+			})
+		}
+
+		rounds = append(rounds, &pack.RoundResult{
+			Performance:           i + 1,
+			Round:                 rr.Round,
+			PlayerID:              rr.PlayerUUID,
+			SelectedCard:          rr.SelectedCard,
+			ScoreInCents:          rr.ScoreInCents,
+			NumberOfScores:        rr.NumberOfScores,
+			NumberOfInterceptions: rr.NumberOfInterceptions,
+			InterceptedBy:         interceptedBy,
+			Skipped:               rr.Skipped,
+		})
+	}
+
+	sessionRankings := make([]*pack.SessionResult, 0)
+	for i, se := range l.leaderboard.Rank() {
+		name := se.Name
+		sessionRankings = append(sessionRankings, &pack.SessionResult{
+			Player:       *pack.CreatePlayer(&se.UUID, &name),
+			Rank:         i + 1,
+			ScoreInCents: se.ScoreInCents,
+			GamesPlayed:  se.GamesPlayed,
+		})
+	}
+
+	var aa *pack.AudienceAward
+	if winner := gs.GetAudienceAwardWinner(); winner != nil {
+		name := winner.Name
+		aa = &pack.AudienceAward{
+			Player:                *pack.CreatePlayer(&winner.UUID, &name),
+			AverageScoreInCents:   winner.AverageAudienceScoreInCents(),
+			NumberOfAudienceVotes: winner.NumberOfAudienceVotes,
+		}
+	}
+
+	return pack.MarshalGameFinishedMessage(rankings, rounds, sessionRankings, aa)
+}
+
+// Starts the deadline for the current phase and tells clients how long they have, onExpire is invoked with the lobby's mutex held.
+// Phases without a positive deadline wait indefinitely.
+func (l *Lobby) startPhaseTimer(d time.Duration, onExpire func()) {
+	l.stopPhaseTimer()
+
+	if d <= 0 {
+		return
+	}
+
+	var t *game.PausableTimer
+	t = game.StartPausableTimer(d, func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		// The deadline may have been replaced while this callback was waiting on the mutex
+		if l.closed || l.phaseTimer != t {
+			return
+		}
+
+		l.phaseTimer = nil
+		onExpire()
+	})
+	l.phaseTimer = t
+
+	// The deadline waits for the host if the phase started while the game was paused or they were reconnecting
+	if l.timersHeld() {
+		t.Pause()
+	}
+
+	l.broadcastToClients(l.marshalPhaseCountdown())
+}
+
+// Stops the deadline for the current phase, if there is one.
+func (l *Lobby) stopPhaseTimer() {
+	l.phaseTimer.Stop()
+	l.phaseTimer = nil
+}
+
+// Builds and marshals a countdown for the deadline of the current phase.
+func (l *Lobby) marshalPhaseCountdown() []byte {
+	remaining := int(math.Ceil(l.phaseTimer.Remaining().Seconds()))
+	return pack.MarshalPhaseCountdownMessage(l.currentPhase().String(), remaining, l.phaseTimer.IsPaused())
 }
 
-// Broadcasts a message to the host game client and all connected clients.
+// Broadcasts a message to the host game client and all connected clients, including the audience.
 func (l *Lobby) broadcastToClients(msg []byte) {
-	l.hostGameClient.conn.WriteMessage(websocket.TextMessage, msg)
+	l.unicastToGameClient(msg)
+	l.unicastToWebClients(msg)
+	l.unicastToAudience(msg)
+}
+
+// Tells every client that the next round has started, only players are asked for the passed number of jobs.
+// Judges that joined mid-game and the audience don't submit jobs, so they're sent a round start that doesn't ask for any.
+func (l *Lobby) broadcastRoundStart(numberOfJobs int) {
+	gs := l.gameState
+	prompt := pack.MarshalRoundStartMessage(gs.Round, gs.TotalRounds, numberOfJobs)
+	wait := pack.MarshalRoundStartMessage(gs.Round, gs.TotalRounds, 0)
+
+	l.unicastToGameClient(prompt)
 	for c := range l.webClients {
-		c.conn.WriteMessage(websocket.TextMessage, msg)
+		if _, isPlayer := gs.PlayersToPlayerState[c.UUID]; isPlayer {
+			c.Send(prompt)
+		} else {
+			c.Send(wait)
+		}
 	}
+	l.unicastToAudience(wait)
 }
 
 // Sends a message to the host game client.
 func (l *Lobby) unicastToGameClient(msg []byte) {
-	l.hostGameClient.conn.WriteMessage(websocket.TextMessage, msg)
+	l.hostGameClient.Send(msg)
 }
 
 // Sends a message to all connected web clients.
 func (l *Lobby) unicastToWebClients(msg []byte) {
 	for c := range l.webClients {
-		c.conn.WriteMessage(websocket.TextMessage, msg)
+		c.Send(msg)
 	}
 }
 
-// Sends a message directly to a specific socket
-func (l *Lobby) dmTargetSocket(sdr *SocketDMRequest) {
-	sdr.DestSocket.WriteMessage(websocket.TextMessage, sdr.Data)
+// Sends a message to all connected audience clients.
+func (l *Lobby) unicastToAudience(msg []byte) {
+	for c := range l.audienceClients {
+		c.Send(msg)
+	}
+}
+
+// Sends a message to the client on a specific socket.
+func (l *Lobby) dmTargetSocket(conn *websocket.Conn, msg []byte) {
+	if c, ok := l.socketsToClients[conn]; ok {
+		c.Send(msg)
+	}
+}
+
+// Records the measured round-trip latency of a socket and reports it to the host, safe to call from any goroutine.
+func (l *Lobby) recordLatency(conn *websocket.Conn, latency time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	c, ok := l.socketsToClients[conn]
+	if !ok || l.closed {
+		return
+	}
+
+	c.latency = latency
+	logger.Verbosef("[server] Client %s latency: %s", c.UUID.String(), latency.String())
+
+	if c.clientType == Web {
+		l.unicastToGameClient(pack.MarshalPlayerLatencyMessage(&c.UUID, int(latency.Milliseconds())))
+	}
+}
+
+// Suspends the client on a dropped socket, safe to call from any goroutine.
+func (l *Lobby) requestSuspend(conn *websocket.Conn) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.suspendClient(conn)
+}
+
+// Re-binds a client to a new socket, safe to call from any goroutine.
+func (l *Lobby) requestRejoin(conn *websocket.Conn, ct ClientType, rt string, ip string) *Client {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.rejoinClient(conn, ct, rt, ip)
+}
+
+// Checks if a resume token or remote IP has been banned from the lobby, safe to call from any goroutine.
+func (l *Lobby) requestBanCheck(rt string, ip string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.isBanned(rt, ip)
+}
+
+// Retrieves the phase of the lobby's game, lobbies without a game are in the lobby phase.
+func (l *Lobby) currentPhase() game.Phase {
+	if l.gameState == nil {
+		return game.LobbyPhase
+	}
+
+	return l.gameState.Phase
+}
+
+// Checks if the passed display name is in use by anyone in the lobby, names are compared case-insensitively.
+func (l *Lobby) isNameTaken(name string) bool {
+	for _, clients := range []map[*Client]bool{l.webClients, l.audienceClients} {
+		for c := range clients {
+			if strings.EqualFold(c.Name, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Resolves a sanitized display name against the names already in the lobby.
+// Duplicate names are given a numbered suffix if configured to, otherwise they're rejected.
+func (l *Lobby) resolveName(name string) (string, error) {
+	if !l.isNameTaken(name) {
+		return name, nil
+	}
+
+	if !game.Config.Limits.SuffixDuplicateNames {
+		return "", pack.NewCodedError(pack.ErrorNameTaken, "Lobby join request was received, but the player name is already taken.")
+	}
+
+	base := []rune(name)
+	for i := 2; ; i++ {
+		suffix := " " + strconv.Itoa(i)
+
+		// The suffix replaces the end of the name if it would push it over the length limit,
+		// once there's no room left for the name itself every suffix that fits has been taken
+		maxBaseLength := game.Config.Limits.MaximumNameLength - utf8.RuneCountInString(suffix)
+		if maxBaseLength < 1 {
+			return "", pack.NewCodedError(pack.ErrorNameTaken, "Lobby join request was received, but the player name is already taken and no numbered suffix fits within the name length limit.")
+		}
+		if len(base) > maxBaseLength {
+			base = base[:maxBaseLength]
+		}
+
+		if candidate := strings.TrimSpace(string(base)) + suffix; !l.isNameTaken(candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// Retrieves the web or audience client with the passed UUID, returns nil if there isn't one.
+func (l *Lobby) getClientWithUUID(id uuid.UUID) *Client {
+	for _, clients := range []map[*Client]bool{l.webClients, l.audienceClients} {
+		for c := range clients {
+			if c.UUID == id {
+				return c
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks if the passed socket belongs to the lobby's host.
+func (l *Lobby) isHostSocket(c *websocket.Conn) bool {
+	return l.hostGameClient.IsConnected() && l.hostGameClient.conn == c
 }
 
 // Retrieves a client associated with the current socket connection.
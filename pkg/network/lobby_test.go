@@ -0,0 +1,31 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/game"
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/pack"
+)
+
+func TestResolveNameCatchesZeroWidthDuplicates(t *testing.T) {
+	game.Config.Limits.SuffixDuplicateNames = true
+
+	l := CreateLobby("ABCD", nil, nil)
+	l.webClients[&Client{clientType: Web, Name: "Sam"}] = true
+
+	lc := "ABCD"
+	name := "Sa\u200bm"
+	ljam := &pack.LobbyJoinAttemptMessage{Name: &name}
+	ljam.LobbyCode = &lc
+	if err := ljam.Verify(&lc, game.Config.Limits.MinimumNameLength, game.Config.Limits.MaximumNameLength); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := l.resolveName(*ljam.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != "Sam 2" {
+		t.Errorf("name differing from %q by a zero-width space resolved to %q, want %q", "Sam", resolved, "Sam 2")
+	}
+}
@@ -0,0 +1,107 @@
+package network
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/pkg/game"
+)
+
+const (
+	maxLobbyCodeGenerationAttempts = 64
+)
+
+// Tracks the lobby codes that are in use, as well as recently released codes that are cooling down.
+type LobbyCodeRegistry struct {
+	active    map[string]bool
+	cooldowns map[string]time.Time
+	mutex     sync.Mutex
+}
+
+// Creates an empty lobby code registry.
+func CreateLobbyCodeRegistry() *LobbyCodeRegistry {
+	return &LobbyCodeRegistry{
+		active:    make(map[string]bool),
+		cooldowns: make(map[string]time.Time),
+	}
+}
+
+// Generates and reserves a random lobby code that isn't in use or cooling down.
+func (r *LobbyCodeRegistry) Generate() (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	alphabet := []rune(game.Config.LobbyCodes.Alphabet)
+	length := game.Config.LobbyCodes.Length
+	if len(alphabet) == 0 || length <= 0 {
+		return "", errors.New("Lobby code alphabet and length must be configured.")
+	}
+
+	r.pruneCooldowns()
+
+	for i := 0; i < maxLobbyCodeGenerationAttempts; i++ {
+		code, err := randomLobbyCode(alphabet, length)
+		if err != nil {
+			return "", err
+		}
+
+		if r.active[code] {
+			continue
+		}
+
+		if _, ok := r.cooldowns[code]; ok {
+			continue
+		}
+
+		r.active[code] = true
+		return code, nil
+	}
+
+	return "", errors.New("Failed to generate an unused lobby code, the code space may be exhausted.")
+}
+
+// Releases a lobby code, blocking it from reuse until its cooldown expires.
+func (r *LobbyCodeRegistry) Release(code string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.active, code)
+	r.cooldowns[code] = time.Now().Add(game.Config.GetTypedLobbyCodeCooldownSeconds())
+}
+
+// Removes lobby codes whose cooldowns have expired.
+func (r *LobbyCodeRegistry) pruneCooldowns() {
+	now := time.Now()
+
+	for code, expiry := range r.cooldowns {
+		if now.After(expiry) {
+			delete(r.cooldowns, code)
+		}
+	}
+}
+
+// Builds a random lobby code of a given length from the passed alphabet.
+func randomLobbyCode(alphabet []rune, length int) (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(alphabet)))
+
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteRune(alphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// Normalizes a lobby code entered by a player so that casing and surrounding whitespace are ignored.
+func NormalizeLobbyCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
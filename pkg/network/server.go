@@ -2,8 +2,13 @@ package network
 
 import (
 	// "encoding/json"
+	"math"
+	"math/rand"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/logger"
@@ -20,14 +25,15 @@ type WebSocketServer struct {
 	HTTPTimeout    time.Duration
 	MaxHeaderBytes int
 	listener       net.Listener
-	lobby          *Lobby
 	upgrader       websocket.Upgrader
-	gameState      *game.State
-	pingTimer      *time.Timer
+	lobbies        map[string]*Lobby
+	lobbiesMutex   sync.RWMutex
+	lobbyCodes     *LobbyCodeRegistry
 }
 
 func (server *WebSocketServer) Start() {
-	server.lobby = nil
+	server.lobbies = make(map[string]*Lobby)
+	server.lobbyCodes = CreateLobbyCodeRegistry()
 	http.HandleFunc("/connect", func(w http.ResponseWriter, r *http.Request) {
 		server.upgrader = websocket.Upgrader{
 			CheckOrigin: func(_ *http.Request) bool { return true },
@@ -58,6 +64,26 @@ func serveWebSocket(s *WebSocketServer, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// The lobby this socket belongs to, assigned once it creates or joins one
+	var lobby *Lobby
+
+	// The address of the remote peer, used to enforce bans
+	ip := remoteIP(r)
+
+	// Sockets that never join a lobby aren't pinged, so they're dropped if they stay silent for the pong wait
+	c.SetReadDeadline(time.Now().Add(pongWaitSeconds))
+
+	// Pongs answer the pings pumped by the client's writer, they extend the read deadline and report latency
+	c.SetPongHandler(func(appData string) error {
+		c.SetReadDeadline(time.Now().Add(pongWaitSeconds))
+
+		if latency, ok := measurePongLatency(appData); ok && lobby != nil {
+			lobby.recordLatency(c, latency)
+		}
+
+		return nil
+	})
+
 	for {
 		_, msg, err := c.ReadMessage()
 
@@ -67,25 +93,18 @@ func serveWebSocket(s *WebSocketServer, w http.ResponseWriter, r *http.Request)
 				logger.Errorf("[server] Error reading message: %v", err)
 			}
 
-			// If the lobby has been created, treat this codepath like a disconnect
-			if s.lobby != nil && c != nil {
-				disconnectedClient := s.lobby.GetClientWithSocket(c)
-				if disconnectedClient.clientType == Game {
-					s.lobby.disconnect <- c
-					s.lobby.closeLobby()
-					s.lobby = nil
-
-					if s.gameState != nil {
-						s.gameState.Reset()
-					}
-				} else {
-					disconnectedClient.CloseClient()
-				}
+			// If the socket belongs to a lobby, treat this codepath like a disconnect
+			// The client is suspended until it rejoins, if the host never returns the lobby is closed
+			if lobby != nil && c != nil {
+				lobby.requestSuspend(c)
 			}
 
 			break
 		}
 
+		// Any message shows the peer is still alive
+		c.SetReadDeadline(time.Now().Add(pongWaitSeconds))
+
 		strMsg := string(msg)
 		if end := strMsg[len(strMsg):]; end == "\n" {
 			logger.Verbosef("[payload] %s", strMsg[:len(strMsg)-1])
@@ -93,115 +112,449 @@ func serveWebSocket(s *WebSocketServer, w http.ResponseWriter, r *http.Request)
 			logger.Verbosef("[payload] %s", strMsg)
 		}
 
-		switch msgJSON := json.UnmarshalJSON[pack.Message](msg); msgJSON.MessageType {
+		msgJSON, err := json.TryUnmarshalJSON[pack.Message](msg)
+		if err != nil {
+			s.rejectRequest(lobby, c, "", pack.NewCodedErrorf(pack.ErrorMalformedJSON, "Failed to parse message: %v", err))
+			continue
+		}
+
+		switch msgJSON.MessageType {
 		case pack.CreateLobby:
-			s.tryCreateLobby(c)
+			clm, err := unmarshalBody[pack.CreateLobbyMessage](msgJSON.MessageType, msg)
+			if err != nil {
+				s.rejectRequest(lobby, c, msgJSON.MessageType, err)
+				continue
+			}
+
+			if l := s.tryCreateLobby(lobby, c, &clm); l != nil {
+				lobby = l
+			}
 		case pack.LobbyJoinAttempt:
-			ljam := json.UnmarshalJSON[pack.LobbyJoinAttemptMessage](msg)
-			s.tryAddClientToLobby(c, &ljam)
-		case pack.GameStart:
-			s.startGame(c)
-		case pack.JobSubmitted:
-			jsm := json.UnmarshalJSON[pack.JobSubmittedMessage](msg)
-			s.addJobToGameState(c, &jsm)
-		case pack.CardData:
-			cd := json.UnmarshalJSON[pack.CardDataMessage](msg)
-			s.submitCardToGameState(c, cd)
-		case pack.InterceptionCardData:
-			icd := json.UnmarshalJSON[pack.CardDataMessage](msg)
-			s.handleCardInterception(c, icd)
-		case pack.ScoreSubmission:
-			ss := json.UnmarshalJSON[pack.ScoreSubmissionMessage](msg)
-			s.handleScoreSubmission(c, ss)
+			ljam, err := unmarshalBody[pack.LobbyJoinAttemptMessage](msgJSON.MessageType, msg)
+			if err != nil {
+				s.rejectRequest(lobby, c, msgJSON.MessageType, err)
+				continue
+			}
+
+			if l := s.tryAddClientToLobby(lobby, c, &ljam, ip); l != nil {
+				lobby = l
+			}
+		case pack.LobbyRejoin:
+			lrm, err := unmarshalBody[pack.LobbyRejoinMessage](msgJSON.MessageType, msg)
+			if err != nil {
+				s.rejectRequest(lobby, c, msgJSON.MessageType, err)
+				continue
+			}
+
+			if l := s.tryRejoinLobby(lobby, c, &lrm, ip); l != nil {
+				lobby = l
+			}
+		case pack.HostRejoin:
+			hrm, err := unmarshalBody[pack.HostRejoinMessage](msgJSON.MessageType, msg)
+			if err != nil {
+				s.rejectRequest(lobby, c, msgJSON.MessageType, err)
+				continue
+			}
+
+			if l := s.tryReclaimLobby(lobby, c, &hrm, ip); l != nil {
+				lobby = l
+			}
+		case pack.GameStart, pack.JobSubmitted, pack.CardData, pack.InterceptionCardData, pack.ScoreSubmission,
+			pack.HostPause, pack.HostResume, pack.HostSkipPlayer, pack.PlayAgain, pack.KickPlayer:
+			s.handleLobbyMessage(lobby, c, msgJSON.MessageType, msg)
 		default:
-			rejectConnection(c)
+			s.rejectRequest(lobby, c, msgJSON.MessageType, pack.NewCodedErrorf(pack.ErrorUnknownMessageType, "Unknown message type %s.", msgJSON.MessageType))
 		}
 	}
 }
 
-// Attempts to create a new lobby on the server and initialize the "hosting" game client.
-// Note that only one lobby can exist on the server at a given time, so redundant requests to create lobbies are ignored.
-func (s *WebSocketServer) tryCreateLobby(c *websocket.Conn) {
-	if s.lobby != nil {
-		logger.Warn("[server] Attempting to create another lobby on this server when one already exists or is in-progress. Ignoring.")
+// Routes a message from a socket to the handlers of the lobby it belongs to.
+// The lobby's mutex is held for the duration of the handler.
+func (s *WebSocketServer) handleLobbyMessage(l *Lobby, c *websocket.Conn, mt pack.MessageType, msg []byte) {
+	if l == nil {
+		rejectConnection(c, mt, pack.NewCodedError(pack.ErrorNotInLobby, "Request was received, but the socket doesn't belong to a lobby."))
 		return
 	}
 
-	s.lobby = CreateLobby()
-	go s.lobby.run()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		return
+	}
+
+	// Messages that aren't legal in the current phase are rejected before reaching their handler
+	if err := l.currentPhase().CheckMessageAllowed(mt); err != nil {
+		l.rejectSocket(c, mt, err)
+		return
+	}
+
+	switch mt {
+	case pack.GameStart:
+		s.startGame(l, c)
+	case pack.JobSubmitted:
+		jsm, err := unmarshalBody[pack.JobSubmittedMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.addJobToGameState(l, c, &jsm)
+	case pack.CardData:
+		cd, err := unmarshalBody[pack.CardDataMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.submitCardToGameState(l, c, cd)
+	case pack.InterceptionCardData:
+		icd, err := unmarshalBody[pack.CardDataMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.handleCardInterception(l, c, icd)
+	case pack.ScoreSubmission:
+		ss, err := unmarshalBody[pack.ScoreSubmissionMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.handleScoreSubmission(l, c, ss)
+	case pack.HostPause:
+		s.pauseGame(l, c)
+	case pack.HostResume:
+		s.resumeGame(l, c)
+	case pack.HostSkipPlayer:
+		s.skipCurrentPlayer(l, c)
+	case pack.PlayAgain:
+		pam, err := unmarshalBody[pack.PlayAgainMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.playAgain(l, c, pam)
+	case pack.KickPlayer:
+		kpm, err := unmarshalBody[pack.KickPlayerMessage](mt, msg)
+		if err != nil {
+			l.rejectSocket(c, mt, err)
+			return
+		}
+
+		s.kickPlayer(l, c, kpm)
+	}
+}
+
+// Parses the body of a request, returns a malformed_json error if the body doesn't match the structure of its message type.
+func unmarshalBody[T any](mt pack.MessageType, msg []byte) (T, error) {
+	body, err := json.TryUnmarshalJSON[T](msg)
+	if err != nil {
+		return body, pack.NewCodedErrorf(pack.ErrorMalformedJSON, "Failed to parse %s message: %v", mt, err)
+	}
+
+	return body, nil
+}
+
+// Attempts to create a new lobby on the server and initialize the "hosting" game client.
+// Any number of lobbies may exist at once, but a socket that already belongs to a lobby can't create another one.
+func (s *WebSocketServer) tryCreateLobby(l *Lobby, c *websocket.Conn, clm *pack.CreateLobbyMessage) *Lobby {
+	if l != nil {
+		l.requestReject(c, pack.CreateLobby, pack.NewCodedError(pack.ErrorAlreadyInLobby, "Attempting to create a lobby from a socket that already belongs to one."))
+		return nil
+	}
+
+	// Lobbies score with the configured aggregator unless the host picks another one
+	agg := game.Config.GetScoreAggregator()
+	if clm.ScoreAggregator != nil {
+		requested, err := game.CreateScoreAggregator(*clm.ScoreAggregator, game.Config.Scoring.TrimmedMeanCutoff)
+		if err != nil {
+			rejectConnection(c, pack.CreateLobby, pack.NewCodedErrorf(pack.ErrorUnknownAggregator, "Lobby creation request was received, but the score aggregator can't be used: %v", err))
+			return nil
+		}
+
+		agg = requested
+	}
+
+	l, err := s.registerLobby()
+	if err != nil {
+		logger.Errorf("[server] Failed to create lobby: %v", err)
+		rejectConnection(c, pack.CreateLobby, pack.NewCodedError(pack.ErrorLobbyCreation, "Failed to create a lobby, try again later."))
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if clm.Seed != nil {
+		l.seed = *clm.Seed
+	}
+	l.scoreAggregator = agg
+
+	client := CreateClient(l, c, Game)
+	l.registerClient(client)
+
+	return l
+}
+
+// Creates a new lobby with an unused lobby code and adds it to the server's registry.
+func (s *WebSocketServer) registerLobby() (*Lobby, error) {
+	lobbyCode, err := s.lobbyCodes.Generate()
+	if err != nil {
+		return nil, err
+	}
 
-	client := CreateClient(s.lobby, c, Game)
-	client.lobby.register <- client
+	s.lobbiesMutex.Lock()
+	defer s.lobbiesMutex.Unlock()
+
+	l := CreateLobby(lobbyCode, s.removeLobby, s.handlePlayerLeft)
+	s.lobbies[lobbyCode] = l
+
+	logger.Infof("[server] Created lobby %s, %d lobbies active.", lobbyCode, len(s.lobbies))
+
+	return l, nil
 }
 
-// Attempts to add a web client to the server's lobby.
+// Removes a lobby from the server's registry and releases its lobby code.
+func (s *WebSocketServer) removeLobby(l *Lobby) {
+	s.lobbiesMutex.Lock()
+	defer s.lobbiesMutex.Unlock()
+
+	delete(s.lobbies, l.lobbyCode)
+	s.lobbyCodes.Release(l.lobbyCode)
+
+	logger.Infof("[server] Removed lobby %s, %d lobbies active.", l.lobbyCode, len(s.lobbies))
+}
+
+// Retrieves the lobby associated with a lobby code, returns nil if no such lobby exists.
+func (s *WebSocketServer) getLobby(lobbyCode *string) *Lobby {
+	if lobbyCode == nil {
+		return nil
+	}
+
+	s.lobbiesMutex.RLock()
+	defer s.lobbiesMutex.RUnlock()
+
+	return s.lobbies[NormalizeLobbyCode(*lobbyCode)]
+}
+
+// Attempts to add a web client to the lobby matching the requested lobby code.
 // This operation requires that messages sent by the client adhere to the `LobbyJoinAttemptMessage` specification.
-func (s *WebSocketServer) tryAddClientToLobby(c *websocket.Conn, ljam *pack.LobbyJoinAttemptMessage) {
-	if s.lobby == nil {
-		logger.Warn("[server] Lobby join request was recevied, but no lobby has been created yet.")
-		rejectConnection(c)
-		return
+func (s *WebSocketServer) tryAddClientToLobby(l *Lobby, c *websocket.Conn, ljam *pack.LobbyJoinAttemptMessage, ip string) *Lobby {
+	if l != nil {
+		l.requestReject(c, pack.LobbyJoinAttempt, pack.NewCodedError(pack.ErrorAlreadyInLobby, "Lobby join request was received from a socket that already belongs to a lobby."))
+		return nil
 	}
 
-	if err := ljam.Verify(&s.lobby.lobbyCode); err != nil {
-		logger.Warnf("[server] Lobby join failure: %v", err)
-		rejectConnection(c)
-		return
+	if ljam.LobbyCode == nil {
+		rejectConnection(c, pack.LobbyJoinAttempt, pack.NewCodedError(pack.ErrorLobbyCodeMissing, "Lobby join request was received, but no lobby code was specified."))
+		return nil
+	}
+
+	l = s.getLobby(ljam.LobbyCode)
+	if l == nil {
+		rejectConnection(c, pack.LobbyJoinAttempt, pack.NewCodedError(pack.ErrorWrongLobbyCode, "Lobby join request was recevied, but no lobby exists with the requested code."))
+		return nil
+	}
+
+	normalizedLobbyCode := NormalizeLobbyCode(*ljam.LobbyCode)
+	ljam.LobbyCode = &normalizedLobbyCode
+
+	limits := game.Config.Limits
+	if err := ljam.Verify(&l.lobbyCode, limits.MinimumNameLength, limits.MaximumNameLength); err != nil {
+		rejectConnection(c, pack.LobbyJoinAttempt, err)
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.closed {
+		rejectConnection(c, pack.LobbyJoinAttempt, pack.NewCodedError(pack.ErrorWrongLobbyCode, "Lobby join request was received, but the lobby has closed."))
+		return nil
+	}
+
+	if l.isBanned("", ip) {
+		rejectConnection(c, pack.LobbyJoinAttempt, pack.NewCodedError(pack.ErrorBanned, "Lobby join request was received, but the sender has been banned from the lobby."))
+		return nil
+	}
+
+	// The player cap doesn't apply to the audience, so players joining a full lobby are admitted to its audience instead
+	if !ljam.Audience && len(l.webClients) >= limits.MaximumNumberOfPlayers {
+		logger.Verbosef("[server] Lobby %s already has %d players, admitting the joining player to the audience.", l.lobbyCode, limits.MaximumNumberOfPlayers)
+		ljam.Audience = true
+	}
+
+	name, err := l.resolveName(*ljam.Name)
+	if err != nil {
+		rejectConnection(c, pack.LobbyJoinAttempt, err)
+		return nil
+	}
+	ljam.Name = &name
+
+	// Audience members watch and vote, but never play
+	if ljam.Audience {
+		client := CreateClient(l, c, Audience)
+		client.Name = *ljam.Name
+		client.remoteIP = ip
+		l.registerClient(client)
+		l.replaySessionState(client)
+
+		l.unicastToGameClient(pack.MarshalAudienceUpdateMessage(pack.AudienceJoined, &client.UUID, &client.Name, len(l.audienceClients)))
+
+		return l
 	}
 
-	client := CreateClient(s.lobby, c, Web)
+	client := CreateClient(l, c, Web)
 	client.Name = *ljam.Name
-	client.lobby.register <- client
+	client.remoteIP = ip
+	l.registerClient(client)
+
+	// Players that join a running game judge it and become full players in the next one
+	gameRunning := l.currentPhase() != game.LobbyPhase && l.currentPhase() != game.FinishedPhase
+	if gameRunning {
+		l.gameState.AddJudge(client.UUID)
+		l.replaySessionState(client)
+	}
 
 	// Send a message to the game client indicating that a web client has connected.
 	pjam := pack.CreatePlayerJoinedMessage(&client.UUID, &client.Name)
-	client.lobby.unicastGame <- json.MarshalJSONBytes[pack.PlayerJoinedMessage](pjam)
+	pjam.JudgeOnly = gameRunning
+	l.unicastToGameClient(json.MarshalJSONBytes[pack.PlayerJoinedMessage](pjam))
+
+	return l
 }
 
-// Echoes a start game request to all clients on the server.
-func (s *WebSocketServer) startGame(c *websocket.Conn) {
-	if s.lobby == nil {
-		logger.Warn("[server] Start game request was recevied, but no lobby has been created yet.")
-		rejectConnection(c)
-		return
+// Attempts to resume a web client's session in a lobby using the resume token it was issued when it joined.
+func (s *WebSocketServer) tryRejoinLobby(l *Lobby, c *websocket.Conn, lrm *pack.LobbyRejoinMessage, ip string) *Lobby {
+	if l != nil {
+		l.requestReject(c, pack.LobbyRejoin, pack.NewCodedError(pack.ErrorAlreadyInLobby, "Lobby rejoin request was received from a socket that already belongs to a lobby."))
+		return nil
+	}
+
+	if err := lrm.Verify(); err != nil {
+		rejectConnection(c, pack.LobbyRejoin, err)
+		return nil
+	}
+
+	l = s.getLobby(lrm.LobbyCode)
+	if l == nil {
+		rejectConnection(c, pack.LobbyRejoin, pack.NewCodedError(pack.ErrorWrongLobbyCode, "Lobby rejoin request was received, but no lobby exists with the requested code."))
+		return nil
+	}
+
+	if l.requestBanCheck(*lrm.ResumeToken, ip) {
+		rejectConnection(c, pack.LobbyRejoin, pack.NewCodedError(pack.ErrorBanned, "Lobby rejoin request was received, but the sender has been banned from the lobby."))
+		return nil
+	}
+
+	if client := l.requestRejoin(c, Web, *lrm.ResumeToken, ip); client == nil {
+		rejectConnection(c, pack.LobbyRejoin, pack.NewCodedError(pack.ErrorInvalidResumeToken, "Lobby rejoin request was received, but the resume token has expired or is unknown."))
+		return nil
+	}
+
+	return l
+}
+
+// Attempts to restore a disconnected game client as the host of its lobby using the host secret it was issued.
+func (s *WebSocketServer) tryReclaimLobby(l *Lobby, c *websocket.Conn, hrm *pack.HostRejoinMessage, ip string) *Lobby {
+	if l != nil {
+		l.requestReject(c, pack.HostRejoin, pack.NewCodedError(pack.ErrorAlreadyInLobby, "Host rejoin request was received from a socket that already belongs to a lobby."))
+		return nil
+	}
+
+	if err := hrm.Verify(); err != nil {
+		rejectConnection(c, pack.HostRejoin, err)
+		return nil
+	}
+
+	l = s.getLobby(hrm.LobbyCode)
+	if l == nil {
+		rejectConnection(c, pack.HostRejoin, pack.NewCodedError(pack.ErrorWrongLobbyCode, "Host rejoin request was received, but no lobby exists with the requested code."))
+		return nil
+	}
+
+	if client := l.requestRejoin(c, Game, *hrm.HostSecret, ip); client == nil {
+		rejectConnection(c, pack.HostRejoin, pack.NewCodedError(pack.ErrorInvalidHostSecret, "Host rejoin request was received, but the host secret was incorrect."))
+		return nil
 	}
 
-	clients := s.lobby.webClients
+	return l
+}
+
+// Echoes a start game request to all clients in the lobby, returns false if the game couldn't be started.
+func (s *WebSocketServer) startGame(l *Lobby, c *websocket.Conn) bool {
+	clients := l.webClients
 	// todo: Remove production environment constraint for minimum number of players?
 	minNumberOfPlayers := game.Config.Limits.MinimumNumberOfPlayers
 	if utils.IsProductionEnv() && len(clients) < minNumberOfPlayers {
-		logger.Warn("Start game request was received, but the lobby has less than the minimum amount of clients connected that are required to play.")
-		rejectConnection(c)
-		return
+		l.rejectSocket(c, pack.GameStart, pack.NewCodedErrorf(pack.ErrorNotEnoughPlayers, "Start game request was received, but at least %d players are required to play.", minNumberOfPlayers))
+		return false
 	}
 
-	// This has to be initialized with a list of UUIDs to properly setup the game (for now)
-	// Note: If we want to have drop-in/drop-out play later, we'd have to change this
-	uuids := make([]uuid.UUID, 0)
-	for client := range s.lobby.webClients {
+	// This has to be initialized with a list of UUIDs to properly setup the game,
+	// players that join after this point are admitted as judges until the next game.
+	// Players are ordered by their unique names so that a seed reproduces the same game.
+	players := make([]*Client, 0, len(l.webClients))
+	for client := range l.webClients {
+		players = append(players, client)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+
+	uuids := make([]uuid.UUID, 0, len(players))
+	for _, client := range players {
 		uuids = append(uuids, client.UUID)
 	}
-	s.gameState = game.CreateGameState(uuids)
 
-	sgm := pack.CreateGameStartMessage(s.gameState.JobInputsPerPlayer)
-	s.lobby.broadcast <- json.MarshalJSONBytes[pack.GameStartMessage](sgm)
+	seed := l.resolveGameSeed()
+	logger.Infof("[server] Starting a game in lobby %s with seed %d.", l.lobbyCode, seed)
+	l.gameState = game.CreateGameState(uuids, rand.NewSource(seed))
+	if err := l.gameState.TransitionTo(game.JobSubmissionPhase); err != nil {
+		logger.Errorf("[server] Failed to start the game: %v", err)
+		return false
+	}
+
+	sgm := pack.CreateGameStartMessage(l.gameState.JobInputsPerPlayer)
+	l.broadcastToClients(json.MarshalJSONBytes[pack.GameStartMessage](sgm))
+
+	s.startJobSubmissionTimer(l)
+
+	return true
+}
+
+// Starts the timer players have to submit their jobs in.
+func (s *WebSocketServer) startJobSubmissionTimer(l *Lobby) {
+	l.startPhaseTimer(game.Config.GetTypedJobSubmissionDurationSeconds(), func() {
+		s.expireJobSubmission(l)
+	})
 }
 
 // Some basic pre-requisites to check before executing game state commands
-func (s *WebSocketServer) doesPassPreRequisites(c *websocket.Conn) bool {
-	if s.lobby == nil {
-		logger.Warn("[server] Request to add a job was recevied, but no lobby has been created yet.")
-		rejectConnection(c)
+func (s *WebSocketServer) doesPassPreRequisites(l *Lobby, c *websocket.Conn, mt pack.MessageType) bool {
+	client := l.GetClientWithSocket(c)
+	if client == nil {
+		logger.Warnf("[server] Request with type %s was received, but the connecting socket hasn't registered as a player yet!", mt)
 		return false
 	}
 
-	if client := s.lobby.GetClientWithSocket(c); client == nil {
-		logger.Warnf("[server] Request to add a job was received, but the connecting socket hasn't registered as a player yet!")
+	if l.gameState == nil {
+		l.rejectSocket(c, mt, pack.NewCodedError(pack.ErrorWrongPhase, "Request was received, but the game hasn't started yet!"))
 		return false
 	}
 
-	if s.gameState == nil {
-		logger.Warn("[server] Request to add a job was received, but the game hasn't started yet!")
+	// Judges that joined mid-game can only score, everything else requires a hand
+	isParticipant := l.gameState.IsPlayer(client.UUID)
+	if mt == pack.ScoreSubmission {
+		isParticipant = l.gameState.IsJudge(client.UUID)
+	}
+
+	if !isParticipant {
+		l.rejectSocket(c, mt, pack.NewCodedError(pack.ErrorNotAPlayer, "Request was received, but the sender isn't a player in the current game."))
 		return false
 	}
 
@@ -211,154 +564,581 @@ func (s *WebSocketServer) doesPassPreRequisites(c *websocket.Conn) bool {
 // Adds a job requested by the game state.
 // This also deals out cards to players once they've all submitted as a side effect.
 // todo: Refactor this?
-func (s *WebSocketServer) addJobToGameState(c *websocket.Conn, jsm *pack.JobSubmittedMessage) {
-	if !s.doesPassPreRequisites(c) {
+func (s *WebSocketServer) addJobToGameState(l *Lobby, c *websocket.Conn, jsm *pack.JobSubmittedMessage) {
+	if !s.doesPassPreRequisites(l, c, pack.JobSubmitted) {
 		return
 	}
 
-	if err := jsm.Verify(); err != nil {
-		logger.Warnf("[server] Job submission failure: %v", err)
+	if err := jsm.Verify(game.Config.Limits.MinimumJobLength, game.Config.Limits.MaximumJobLength); err != nil {
+		l.rejectSocket(c, pack.JobSubmitted, err)
 		return
 	}
 
-	client := s.lobby.GetClientWithSocket(c)
-	s.gameState.AddJob(client.UUID, jsm.JobInput)
+	client := l.GetClientWithSocket(c)
+	if err := l.gameState.AddJob(client.UUID, jsm.JobInput); err != nil {
+		l.rejectSocket(c, pack.JobSubmitted, err)
+		return
+	}
 
 	// Once the player has submitted the maximum number of jobs, send infomation to the game client
-	if s.gameState.HasUserFinishedSubmittingJobs(client.UUID) {
+	if l.gameState.HasUserFinishedSubmittingJobs(client.UUID) {
 		pid := pack.MarshalPlayerIDMessage(pack.JobSubmittingFinished, &client.UUID)
-		client.lobby.unicastGame <- pid
+		l.unicastToGameClient(pid)
 	}
 
 	// Once all players have finished submitting jobs
-	if s.gameState.HaveAllUsersFinishedSubmittingJobs() {
+	if l.gameState.HaveAllUsersFinishedSubmittingJobs() {
 		logger.Debug("All users have submitted jobs!")
+		s.startCardSelection(l)
+	}
+}
 
-		// Send a message to the game indicating that players are now receiving their cards
-		rcmGame := pack.MarshalBasicMessage(pack.ReceivedCards)
-		client.lobby.unicastGame <- rcmGame
+// Fills in the jobs of players that didn't submit in time and moves on to card selection.
+func (s *WebSocketServer) expireJobSubmission(l *Lobby) {
+	if l.currentPhase() != game.JobSubmissionPhase {
+		return
+	}
 
-		// Send a message to the web indicating that players are receiving shuffled job cards
-		s.gameState.DealJobsToPlayers()
+	for _, uuid := range l.gameState.FillMissingJobs() {
+		logger.Verbosef("[server] Player %s didn't submit their jobs in time, filled them in from the fallback jobs.", uuid.String())
 
-		for cl := range s.lobby.webClients {
-			uuidCards := s.gameState.PlayersToDealtJobs[cl.UUID]
-			drawnCards := uuidCards[0:(len(uuidCards) - 1)]
-			jobCard := uuidCards[len(uuidCards)-1]
+		pid := pack.MarshalPlayerIDMessage(pack.JobSubmittingFinished, &uuid)
+		l.unicastToGameClient(pid)
+	}
 
-			// Set the player state inside the game state
-			s.gameState.CreatePlayerStateWithUUID(cl.UUID, drawnCards, jobCard)
+	s.startCardSelection(l)
+}
 
-			rcmData := pack.MarshalReceivedCardsMessage(drawnCards, jobCard)
-			cl.lobby.dmSocket <- CreateSocketDMRequest(cl.conn, rcmData)
+// Deals the submitted jobs out to players so that they can select a card for improv.
+func (s *WebSocketServer) startCardSelection(l *Lobby) {
+	if err := l.gameState.TransitionTo(game.CardSelectionPhase); err != nil {
+		logger.Errorf("[server] Failed to move to card selection: %v", err)
+		return
+	}
+
+	// Send a message to the game indicating that players are now receiving their cards
+	rcmGame := pack.MarshalBasicMessage(pack.ReceivedCards)
+	l.unicastToGameClient(rcmGame)
+
+	// Send a message to the web indicating that players are receiving shuffled job cards
+	l.gameState.DealJobsToPlayers()
+
+	for cl := range l.webClients {
+		// Judges that joined mid-game aren't dealt a hand
+		uuidCards, ok := l.gameState.PlayersToDealtJobs[cl.UUID]
+		if !ok || len(uuidCards) == 0 {
+			continue
 		}
+
+		drawnCards := uuidCards[0:(len(uuidCards) - 1)]
+		jobCard := uuidCards[len(uuidCards)-1]
+
+		// Set the player state inside the game state, scores from earlier rounds are kept
+		l.gameState.DealHandToPlayer(cl.UUID, cl.Name, drawnCards, jobCard)
+
+		rcmData := pack.MarshalReceivedCardsMessage(drawnCards, jobCard, l.gameState.Round, l.gameState.TotalRounds)
+		cl.Send(rcmData)
 	}
+
+	l.startPhaseTimer(game.Config.GetTypedCardSelectionDurationSeconds(), func() {
+		s.expireCardSelection(l)
+	})
 }
 
 // Submit a card to the game state, if all users have submitted this starts the timer for the improv round.
-func (s *WebSocketServer) submitCardToGameState(c *websocket.Conn, cd pack.CardDataMessage) {
-	if !s.doesPassPreRequisites(c) {
+func (s *WebSocketServer) submitCardToGameState(l *Lobby, c *websocket.Conn, cd pack.CardDataMessage) {
+	if !s.doesPassPreRequisites(l, c, pack.CardData) {
 		return
 	}
 
 	if err := cd.Verify(); err != nil {
-		logger.Warnf("[server] Card submission failure: %v", err)
+		l.rejectSocket(c, pack.CardData, err)
 		return
 	}
 
 	// Send data back to the game client that this player has selected a role for improv
-	client := s.lobby.GetClientWithSocket(c)
-	if ps, ok := s.gameState.PlayersToPlayerState[client.UUID]; ok {
-		ps.SelectedCard = cd.Card
+	client := l.GetClientWithSocket(c)
+	if ps, ok := l.gameState.PlayersToPlayerState[client.UUID]; ok {
+		card := ps.FindDrawnCard(cd.Card.CardID)
+		if card == nil {
+			l.rejectSocket(c, pack.CardData, pack.NewCodedError(pack.ErrorUnknownCard, "Card submission request was received, but the card isn't in the player's hand."))
+			return
+		}
+
+		ps.SelectedCard = card
 
 		pid := pack.MarshalPlayerIDMessage(pack.CardData, &client.UUID)
-		client.lobby.unicastGame <- pid
+		l.unicastToGameClient(pid)
 	}
 
 	// After each card is submitted, check if improv can be started
-	if s.gameState.CheckStartImprov() {
-		s.startNextImprov(c)
+	if l.gameState.CheckStartImprov() {
+		s.advanceImprovQueue(l)
+	}
+}
+
+// Selects a random card for players that didn't select one in time and starts improv.
+func (s *WebSocketServer) expireCardSelection(l *Lobby) {
+	if l.currentPhase() != game.CardSelectionPhase {
+		return
+	}
+
+	for _, ps := range l.gameState.AutoSelectCards() {
+		logger.Verbosef("[server] Player %s didn't select a card in time, selected one for them.", ps.UUID.String())
+
+		pid := pack.MarshalPlayerIDMessage(pack.CardData, &ps.UUID)
+		l.unicastToGameClient(pid)
+
+		// Let the player know which card they'll be performing with
+		if cl := l.getClientWithUUID(ps.UUID); cl != nil {
+			cl.Send(pack.MarshalCardDataMessage(ps.SelectedCard))
+		}
+	}
+
+	if l.gameState.CheckStartImprov() {
+		s.advanceImprovQueue(l)
 	}
 }
 
-func (s *WebSocketServer) handleCardInterception(c *websocket.Conn, icd pack.CardDataMessage) {
-	if !s.doesPassPreRequisites(c) {
+func (s *WebSocketServer) handleCardInterception(l *Lobby, c *websocket.Conn, icd pack.CardDataMessage) {
+	if !s.doesPassPreRequisites(l, c, pack.InterceptionCardData) {
 		return
 	}
 
 	if err := icd.Verify(); err != nil {
-		logger.Warnf("[server Interception card submission failure: %v", err)
+		l.rejectSocket(c, pack.InterceptionCardData, err)
+		return
+	}
+
+	client := l.GetClientWithSocket(c)
+	card, err := l.gameState.InterceptWithCard(client.UUID, icd.Card.CardID)
+	if err != nil {
+		l.rejectSocket(c, pack.InterceptionCardData, err)
 		return
 	}
 
 	addedTimeSeconds := game.Config.GetTypedInterceptionTimeAddedSeconds()
 	addedTimeInt := game.Config.Times.InterceptionTimeAddedSeconds
 
-	// Reset timer and send interception information back to game client
-	s.gameState.ImprovSession.ResetSessionTimer(addedTimeSeconds)
+	// Reset timer and let everyone know who intercepted, the card is now the performer's role that judges score against
+	l.gameState.ImprovSession.ResetSessionTimer(addedTimeSeconds)
+
+	performer := l.gameState.ImprovSession.GetCurrentImprovPlayer()
+	icm := pack.MarshalInterceptionCardMessage(&client.UUID, &client.Name, &performer.UUID, card, addedTimeInt)
+	l.broadcastToClients(icm)
+}
+
+// Handle a salary vote from an audience member, these are tallied for the audience award and never hold up scoring.
+func (s *WebSocketServer) handleAudienceVote(l *Lobby, client *Client, ss pack.ScoreSubmissionMessage) {
+	if !l.gameState.ImprovSession.SubmitAudienceVoteForPlayer(client.UUID, &ss) {
+		l.rejectSocket(client.conn, pack.ScoreSubmission, pack.NewCodedError(pack.ErrorAlreadyVoted, "Audience vote was received, but the audience member has already voted for this player."))
+		return
+	}
 
-	client := s.lobby.GetClientWithSocket(c)
-	icm := pack.MarshalInterceptionCardMessage(&client.UUID, icd.Card, addedTimeInt)
-	client.lobby.unicastGame <- icm
+	logger.Verbosef("[server] Audience member %s voted in lobby %s.", client.UUID.String(), l.lobbyCode)
 }
 
 // Gets the next player for improv and starts the improv session.
-func (s *WebSocketServer) startNextImprov(c *websocket.Conn) {
-	ps := s.gameState.ImprovSession.GetCurrentImprovPlayer()
+func (s *WebSocketServer) startNextImprov(l *Lobby) {
+	if err := l.gameState.TransitionTo(game.ImprovPhase); err != nil {
+		logger.Errorf("[server] Failed to start the next improv: %v", err)
+		return
+	}
+
+	// Improv rounds are bound by their own timer
+	l.stopPhaseTimer()
+
+	ps := l.gameState.ImprovSession.GetCurrentImprovPlayer()
 
 	// Send an improv start message to the game
-	pism := pack.MarshalPlayerImprovStartMessage(&ps.UUID, ps.SelectedCard, ps.JobCard, game.Config.Times.ImprovRoundDurationSeconds)
-	s.lobby.unicastGame <- pism
+	pism := pack.MarshalPlayerImprovStartMessage(&ps.UUID, ps.SelectedCard, ps.JobCard, game.Config.Times.ImprovRoundDurationSeconds, l.gameState.Round, l.gameState.TotalRounds)
+	l.unicastToGameClient(pism)
 
 	// Send a generic PlayerID to the web client
 	pidm := pack.MarshalPlayerIDMessage(pack.PlayerID, &ps.UUID)
-	s.lobby.unicastWeb <- pidm
+	l.unicastToWebClients(pidm)
+	l.unicastToAudience(pidm)
 
 	// Start the timer since the improv round has begun
-	go s.gameState.ImprovSession.StartTimerForSession(func() {
+	l.gameState.ImprovSession.StartTimerForSession(func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		// The round may have been skipped while this callback was waiting on the mutex
+		if l.closed || l.currentPhase() != game.ImprovPhase {
+			return
+		}
+
+		// Judges score the player once their improv ends
+		if err := l.gameState.TransitionTo(game.ScoringPhase); err != nil {
+			logger.Errorf("[server] Failed to move to scoring: %v", err)
+			return
+		}
+
 		tfm := pack.MarshalBasicMessage(pack.TimerFinished)
-		s.lobby.broadcast <- tfm
+		l.broadcastToClients(tfm)
+
+		// Nobody is waited on if there are no judges left to score the player, e.g., everyone else has left
+		if l.gameState.HaveAllUsersSubmitedScoresForLastImprov() {
+			s.finishScoring(l)
+			return
+		}
+
+		l.startPhaseTimer(game.Config.GetTypedScoringDurationSeconds(), func() {
+			s.expireScoring(l)
+		})
 	})
+
+	// The round waits for the host if it started while the game was paused or they were reconnecting
+	if l.timersHeld() {
+		l.gameState.ImprovSession.PauseSessionTimer()
+	}
 }
 
 // Handle the score submission from the web client and forward the information to the game client.
-func (s *WebSocketServer) handleScoreSubmission(c *websocket.Conn, ss pack.ScoreSubmissionMessage) {
-	if !s.doesPassPreRequisites(c) {
+func (s *WebSocketServer) handleScoreSubmission(l *Lobby, c *websocket.Conn, ss pack.ScoreSubmissionMessage) {
+	if err := ss.Verify(game.Config.Limits.MinimumScoreInCents, game.Config.Limits.MaximumScoreInCents); err != nil {
+		l.rejectSocket(c, pack.ScoreSubmission, err)
 		return
 	}
 
-	s.gameState.ImprovSession.SubmitScoreForPlayer(&ss)
+	if client := l.GetClientWithSocket(c); client != nil && client.clientType == Audience {
+		s.handleAudienceVote(l, client, ss)
+		return
+	}
+
+	if !s.doesPassPreRequisites(l, c, pack.ScoreSubmission) {
+		return
+	}
 
-	client := s.lobby.GetClientWithSocket(c)
+	client := l.GetClientWithSocket(c)
+	replaced, err := l.gameState.ImprovSession.SubmitScoreForPlayer(client.UUID, &ss)
+	if err != nil {
+		l.rejectSocket(c, pack.ScoreSubmission, err)
+		return
+	}
 
-	// Send a player ID message to the Game indicating that this player submitted a score
-	pidm := pack.MarshalPlayerIDMessage(pack.PlayerID, &client.UUID)
-	s.lobby.unicastGame <- pidm
+	// Send a player ID message to the Game indicating that this player submitted a score, a replaced vote has already been announced
+	if replaced {
+		logger.Verbosef("[server] Judge %s replaced their score in lobby %s.", client.UUID.String(), l.lobbyCode)
+	} else {
+		pidm := pack.MarshalPlayerIDMessage(pack.PlayerID, &client.UUID)
+		l.unicastToGameClient(pidm)
+	}
 
 	// Update the improv order to only contain the last items if moving to next improv
-	if s.gameState.HaveAllUsersSubmitedScoresForLastImprov() {
-		poppedPlayer := s.gameState.ImprovSession.PopPlayerOnQueue()
+	if l.gameState.HaveAllUsersSubmitedScoresForLastImprov() {
+		s.finishScoring(l)
+	}
+}
 
-		// Before starting the next improv send the cumulative score for the player that just went
-		ss := pack.MarshalScoreSubmissionMessage(poppedPlayer.ScoreInCents)
-		client.lobby.unicastGame <- ss
+// Treats the scores that weren't submitted in time as abstentions and finishes scoring.
+func (s *WebSocketServer) expireScoring(l *Lobby) {
+	if l.currentPhase() != game.ScoringPhase {
+		return
+	}
 
-		// Set a brief timer for some buffer time between rounds or before finishing the game
-		timer := time.NewTimer(game.Config.GetTypedIntermissionDurationSeconds())
-		<-timer.C
+	if ps := l.gameState.ImprovSession.GetCurrentImprovPlayer(); ps != nil {
+		logger.Verbosef("[server] Scoring for player %s timed out after %d scores, treating the rest as abstentions.", ps.UUID.String(), ps.NumberOfScoresSubmitted)
+	}
 
-		// If the queue has at least one person left, perform another round of improv
-		if s.gameState.ImprovSession.GetNumberOfPlayersLeftToImprov() >= 1 {
-			s.startNextImprov(c)
-		} else {
-			gfm := pack.MarshalBasicMessage(pack.GameFinished)
-			client.lobby.broadcast <- gfm
+	s.finishScoring(l)
+}
+
+// Sends the score for the player that just went and starts the intermission before the next improv or the end of the game.
+func (s *WebSocketServer) finishScoring(l *Lobby) {
+	if err := l.gameState.TransitionTo(game.IntermissionPhase); err != nil {
+		logger.Errorf("[server] Failed to move to intermission: %v", err)
+		return
+	}
+
+	l.stopPhaseTimer()
+
+	roundScore := l.gameState.ImprovSession.ApplyScoresForPlayer(l.scoreAggregator)
+	poppedPlayer := l.gameState.ImprovSession.PopPlayerOnQueue()
+	l.gameState.RecordRound(poppedPlayer, false)
+
+	// Before starting the next improv send the cumulative score for the player that just went, along with what this performance earned
+	ss := pack.MarshalScoreSubmissionMessage(poppedPlayer.ScoreInCents, roundScore)
+	l.unicastToGameClient(ss)
+
+	s.startIntermission(l)
+}
+
+// Starts a brief intermission before the next improv or the end of the game.
+func (s *WebSocketServer) startIntermission(l *Lobby) {
+	// Set a brief timer for some buffer time between rounds or before finishing the game
+	l.intermissionTimer = game.StartPausableTimer(game.Config.GetTypedIntermissionDurationSeconds(), func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		if l.closed {
+			return
 		}
+
+		s.advanceImprovQueue(l)
+	})
+
+	if l.timersHeld() {
+		l.intermissionTimer.Pause()
+	}
+}
+
+// Performs another round of improv if the queue has at least one person left, otherwise starts the next round or finishes the game.
+func (s *WebSocketServer) advanceImprovQueue(l *Lobby) {
+	if l.gameState.ImprovSession.GetNumberOfPlayersLeftToImprov() >= 1 {
+		s.startNextImprov(l)
+		return
+	}
+
+	if l.gameState.HasRoundsLeft() && len(l.gameState.GetPlayerOrder()) > 0 {
+		s.startNextRound(l)
+		return
+	}
+
+	if err := l.gameState.TransitionTo(game.FinishedPhase); err != nil {
+		logger.Errorf("[server] Failed to finish the game: %v", err)
+		return
+	}
+
+	l.stopPhaseTimer()
+	l.leaderboard.AddGame(l.gameState)
+
+	// Send the final standings so that clients don't have to rebuild them
+	l.broadcastToClients(l.marshalGameResults())
+}
+
+// Starts the next round of the game once every player has performed, players either submit new jobs or are dealt new hands straight away.
+func (s *WebSocketServer) startNextRound(l *Lobby) {
+	needsJobs := l.gameState.StartNextRound()
+	logger.Infof("[server] Starting round %d of %d in lobby %s.", l.gameState.Round, l.gameState.TotalRounds, l.lobbyCode)
+
+	if !needsJobs {
+		l.broadcastRoundStart(0)
+		s.startCardSelection(l)
+		return
+	}
+
+	if err := l.gameState.TransitionTo(game.JobSubmissionPhase); err != nil {
+		logger.Errorf("[server] Failed to start the next round: %v", err)
+		return
 	}
+
+	l.broadcastRoundStart(l.gameState.JobInputsPerPlayer)
+	s.startJobSubmissionTimer(l)
 }
 
-// Rejects an incoming connection, responding with a connection rejected message.
-func rejectConnection(c *websocket.Conn) {
-	crm := pack.CreateBasicMessage(pack.ConnectionRejected)
-	c.WriteJSON(crm)
+// Removes a player that left mid-game from the game state so that the current phase can still complete without them.
+func (s *WebSocketServer) handlePlayerLeft(l *Lobby, c *Client) {
+	pidm := pack.MarshalPlayerIDMessage(pack.PlayerLeft, &c.UUID)
+	l.unicastToGameClient(pidm)
+
+	gs := l.gameState
+	if gs == nil {
+		return
+	}
+
+	wasPerforming := false
+	if gs.ImprovSession != nil {
+		if ps := gs.ImprovSession.GetCurrentImprovPlayer(); ps != nil && ps.UUID == c.UUID {
+			wasPerforming = isPerformancePhase(gs.Phase)
+		}
+	}
+
+	gs.RemovePlayer(c.UUID)
+	logger.Verbosef("[server] Removed player %s from the game in lobby %s.", c.UUID.String(), l.lobbyCode)
+
+	switch gs.Phase {
+	case game.JobSubmissionPhase:
+		if gs.HaveAllUsersFinishedSubmittingJobs() {
+			s.startCardSelection(l)
+		}
+	case game.CardSelectionPhase:
+		if gs.CheckStartImprov() {
+			s.advanceImprovQueue(l)
+		}
+	case game.ImprovPhase, game.ScoringPhase:
+		if wasPerforming {
+			s.skipRound(l)
+		} else if gs.Phase == game.ScoringPhase && gs.HaveAllUsersSubmitedScoresForLastImprov() {
+			s.finishScoring(l)
+		}
+	}
+}
+
+// Some basic pre-requisites to check before executing host commands
+func (s *WebSocketServer) doesPassHostPreRequisites(l *Lobby, c *websocket.Conn, mt pack.MessageType) bool {
+	if !l.isHostSocket(c) {
+		l.rejectSocket(c, mt, pack.NewCodedError(pack.ErrorNotHost, "Host request was received, but the sender isn't the lobby's host."))
+		return false
+	}
+
+	return true
+}
+
+// Pauses the game at the host's request, freezing every running timer until the host resumes it.
+func (s *WebSocketServer) pauseGame(l *Lobby, c *websocket.Conn) {
+	if !s.doesPassHostPreRequisites(l, c, pack.HostPause) {
+		return
+	}
+
+	if l.hostPaused {
+		l.rejectSocket(c, pack.HostPause, pack.NewCodedError(pack.ErrorAlreadyPaused, "Pause request was received, but the game is already paused."))
+		return
+	}
+
+	l.hostPaused = true
+	l.pauseTimers()
+
+	logger.Verbosef("[server] Host paused lobby %s.", l.lobbyCode)
+
+	remaining := int(math.Ceil(l.currentTimeRemaining().Seconds()))
+	l.broadcastToClients(pack.MarshalPauseStateMessage(pack.GamePaused, remaining))
+}
+
+// Resumes a game paused by the host, restoring every timer with the time that was remaining when it was paused.
+func (s *WebSocketServer) resumeGame(l *Lobby, c *websocket.Conn) {
+	if !s.doesPassHostPreRequisites(l, c, pack.HostResume) {
+		return
+	}
+
+	if !l.hostPaused {
+		l.rejectSocket(c, pack.HostResume, pack.NewCodedError(pack.ErrorNotPaused, "Resume request was received, but the game isn't paused."))
+		return
+	}
+
+	l.hostPaused = false
+	l.resumeTimers()
+
+	logger.Verbosef("[server] Host resumed lobby %s.", l.lobbyCode)
+
+	remaining := int(math.Ceil(l.currentTimeRemaining().Seconds()))
+	l.broadcastToClients(pack.MarshalPauseStateMessage(pack.GameResumed, remaining))
+
+	if l.phaseTimer.IsRunning() {
+		l.broadcastToClients(l.marshalPhaseCountdown())
+	}
+}
+
+// Skips the player that's currently performing at the host's request, their round ends without any further scoring.
+func (s *WebSocketServer) skipCurrentPlayer(l *Lobby, c *websocket.Conn) {
+	if !s.doesPassHostPreRequisites(l, c, pack.HostSkipPlayer) {
+		return
+	}
+
+	s.skipRound(l)
+}
+
+// Ends the current round without any further scoring and starts the intermission.
+func (s *WebSocketServer) skipRound(l *Lobby) {
+	if err := l.gameState.TransitionTo(game.IntermissionPhase); err != nil {
+		logger.Errorf("[server] Failed to skip the current player: %v", err)
+		return
+	}
+
+	l.gameState.ImprovSession.StopSessionTimer()
+	l.stopPhaseTimer()
+
+	// Scores already submitted for a skipped player still count
+	l.gameState.ImprovSession.ApplyScoresForPlayer(l.scoreAggregator)
+	skippedPlayer := l.gameState.ImprovSession.PopPlayerOnQueue()
+	l.gameState.RecordRound(skippedPlayer, true)
+	logger.Verbosef("[server] Skipped player %s in lobby %s.", skippedPlayer.UUID.String(), l.lobbyCode)
+
+	pidm := pack.MarshalPlayerIDMessage(pack.PlayerSkipped, &skippedPlayer.UUID)
+	l.broadcastToClients(pidm)
+
+	s.startIntermission(l)
+}
+
+// Starts a new game in the same lobby with its current players once the last game has finished.
+// The session leaderboard is cleared unless the host asks to keep scores.
+func (s *WebSocketServer) playAgain(l *Lobby, c *websocket.Conn, pam pack.PlayAgainMessage) {
+	if !s.doesPassHostPreRequisites(l, c, pack.PlayAgain) {
+		return
+	}
+
+	if !s.startGame(l, c) {
+		return
+	}
+
+	if !pam.KeepScores {
+		l.leaderboard = game.CreateSessionLeaderboard()
+	}
+
+	logger.Verbosef("[server] Host started a new game in lobby %s (keep scores: %t).", l.lobbyCode, pam.KeepScores)
+}
+
+// Kicks a player or audience member from the lobby at the host's request, optionally banning them from returning.
+func (s *WebSocketServer) kickPlayer(l *Lobby, c *websocket.Conn, kpm pack.KickPlayerMessage) {
+	if !s.doesPassHostPreRequisites(l, c, pack.KickPlayer) {
+		return
+	}
+
+	if err := kpm.Verify(); err != nil {
+		l.rejectSocket(c, pack.KickPlayer, err)
+		return
+	}
+
+	client := l.getClientWithUUID(*kpm.PlayerID)
+	if client == nil {
+		l.rejectSocket(c, pack.KickPlayer, pack.NewCodedError(pack.ErrorUnknownPlayer, "Kick request was received, but no player in the lobby has the requested ID."))
+		return
+	}
+
+	logger.Verbosef("[server] Host kicked client %s from lobby %s (ban: %t).", client.UUID.String(), l.lobbyCode, kpm.Ban)
+
+	l.kickClient(client, kpm.Ban)
+}
+
+// Retrieves the IP address of the remote peer of a request.
+// Behind a trusted proxy such as Heroku's router the peer is the proxy, so the address the proxy appended to X-Forwarded-For is used instead.
+// The header is ignored otherwise, as clients that connect directly can set it to anything.
+func remoteIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" && game.Config.Network.TrustedProxy {
+		addrs := strings.Split(xff, ",")
+		return strings.TrimSpace(addrs[len(addrs)-1])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Rejects a request from a socket, routing the error through its lobby if the socket belongs to one.
+func (s *WebSocketServer) rejectRequest(l *Lobby, c *websocket.Conn, mt pack.MessageType, err error) {
+	if l == nil {
+		rejectConnection(c, mt, err)
+		return
+	}
+
+	l.requestReject(c, mt, err)
+}
+
+// Rejects a request from a socket that doesn't belong to a lobby, responding with an error message.
+// Only the socket's read loop may call this, since sockets in a lobby are written to by their client's write pump.
+func rejectConnection(c *websocket.Conn, mt pack.MessageType, err error) {
+	logger.Warnf("[server] Rejected %s request: %v", mt, err)
+
+	em := pack.CreateErrorMessage(mt, err)
+	c.WriteJSON(em)
+}
+
+// Rejects a request from a socket in the lobby, responding with an error message.
+// The lobby's mutex must be held when calling this.
+func (l *Lobby) rejectSocket(c *websocket.Conn, mt pack.MessageType, err error) {
+	logger.Warnf("[server] Rejected %s request in lobby %s: %v", mt, l.lobbyCode, err)
+
+	l.dmTargetSocket(c, pack.MarshalErrorMessage(mt, err))
+}
+
+// Rejects a request from a socket in the lobby, safe to call from any goroutine.
+func (l *Lobby) requestReject(c *websocket.Conn, mt pack.MessageType, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.rejectSocket(c, mt, err)
 }
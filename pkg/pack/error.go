@@ -0,0 +1,109 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils/json"
+)
+
+// Stable codes sent to clients to describe why a request was rejected.
+type ErrorCode string
+
+const (
+	ErrorInternal             ErrorCode = "internal"
+	ErrorMalformedJSON        ErrorCode = "malformed_json"
+	ErrorUnknownMessageType   ErrorCode = "unknown_message_type"
+	ErrorLobbyCreation        ErrorCode = "lobby_creation_failed"
+	ErrorUnknownAggregator    ErrorCode = "unknown_score_aggregator"
+	ErrorAlreadyInLobby       ErrorCode = "already_in_lobby"
+	ErrorNotInLobby           ErrorCode = "not_in_lobby"
+	ErrorLobbyCodeMissing     ErrorCode = "lobby_code_missing"
+	ErrorWrongLobbyCode       ErrorCode = "wrong_lobby_code"
+	ErrorNameMissing          ErrorCode = "name_missing"
+	ErrorNameTooShort         ErrorCode = "name_too_short"
+	ErrorNameTooLong          ErrorCode = "name_too_long"
+	ErrorNameTaken            ErrorCode = "name_taken"
+	ErrorResumeTokenMissing   ErrorCode = "resume_token_missing"
+	ErrorInvalidResumeToken   ErrorCode = "invalid_resume_token"
+	ErrorHostSecretMissing    ErrorCode = "host_secret_missing"
+	ErrorInvalidHostSecret    ErrorCode = "invalid_host_secret"
+	ErrorNotEnoughPlayers     ErrorCode = "not_enough_players"
+	ErrorNotAPlayer           ErrorCode = "not_a_player"
+	ErrorAlreadyVoted         ErrorCode = "already_voted"
+	ErrorSelfScoring          ErrorCode = "self_scoring"
+	ErrorScoreOutOfRange      ErrorCode = "score_out_of_range"
+	ErrorNotHost              ErrorCode = "not_host"
+	ErrorPlayerIDMissing      ErrorCode = "player_id_missing"
+	ErrorUnknownPlayer        ErrorCode = "unknown_player"
+	ErrorBanned               ErrorCode = "banned"
+	ErrorAlreadyPaused        ErrorCode = "already_paused"
+	ErrorNotPaused            ErrorCode = "not_paused"
+	ErrorWrongPhase           ErrorCode = "wrong_phase"
+	ErrorJobMissing           ErrorCode = "job_missing"
+	ErrorJobTooShort          ErrorCode = "job_too_short"
+	ErrorJobTooLong           ErrorCode = "job_too_long"
+	ErrorJobRejected          ErrorCode = "job_rejected"
+	ErrorJobDuplicate         ErrorCode = "job_duplicate"
+	ErrorCardMissing          ErrorCode = "card_missing"
+	ErrorMalformedCard        ErrorCode = "malformed_card"
+	ErrorUnknownCard          ErrorCode = "unknown_card"
+	ErrorSelfInterception     ErrorCode = "self_interception"
+	ErrorNoInterceptionsLeft  ErrorCode = "no_interceptions_left"
+	ErrorInterceptionCooldown ErrorCode = "interception_cooldown"
+)
+
+// An error that carries a code which can be reported back to clients.
+type CodedError struct {
+	Code    ErrorCode
+	Message string
+}
+
+// Message sent to clients when one of their requests is rejected.
+// Server -> Web
+// Server -> Game
+type ErrorMessage struct {
+	Message
+	Code                 ErrorCode   `json:"code"`
+	Description          string      `json:"message"`
+	OffendingMessageType MessageType `json:"offending_message_type"`
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// Creates a CodedError.
+func NewCodedError(code ErrorCode, message string) error {
+	return &CodedError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Creates a CodedError with a formatted message.
+func NewCodedErrorf(code ErrorCode, template string, args ...interface{}) error {
+	return NewCodedError(code, fmt.Sprintf(template, args...))
+}
+
+// Creates an ErrorMessage for a rejected request, errors without a code are reported as internal errors.
+func CreateErrorMessage(mt MessageType, err error) *ErrorMessage {
+	code := ErrorInternal
+
+	var ce *CodedError
+	if errors.As(err, &ce) {
+		code = ce.Code
+	}
+
+	return &ErrorMessage{
+		Message:              *CreateBasicMessage(Error),
+		Code:                 code,
+		Description:          err.Error(),
+		OffendingMessageType: mt,
+	}
+}
+
+// Creates and marshals an ErrorMessage.
+func MarshalErrorMessage(mt MessageType, err error) []byte {
+	return json.MarshalJSONBytes[ErrorMessage](CreateErrorMessage(mt, err))
+}
@@ -1,7 +1,7 @@
 package pack
 
 import (
-	"errors"
+	"unicode/utf8"
 
 	"github.com/20TB-ZipBomb/GGJ_Platform/internal/utils/json"
 	"github.com/google/uuid"
@@ -10,14 +10,29 @@ import (
 type MessageType string
 
 const (
-	ConnectionRejected    MessageType = "connection_rejected"
-	Alive                             = "alive"
+	Error                 MessageType = "error"
 	CreateLobby                       = "create_lobby"
 	LobbyCode                         = "lobby_code"
+	HostRejoin                        = "host_rejoin"
+	HostDisconnected                  = "host_disconnected"
+	HostReconnected                   = "host_reconnected"
+	HostPause                         = "host_pause"
+	HostResume                        = "host_resume"
+	HostSkipPlayer                    = "host_skip_player"
+	GamePaused                        = "game_paused"
+	GameResumed                       = "game_resumed"
+	PlayerSkipped                     = "player_skipped"
+	LobbySnapshot                     = "lobby_snapshot"
 	LobbyJoinAttempt                  = "lobby_join_attempt"
+	LobbyRejoin                       = "lobby_rejoin"
 	PlayerID                          = "player_id"
 	PlayerJoined                      = "player_joined"
+	PlayerLeft                        = "player_left"
+	AudienceJoined                    = "audience_joined"
+	AudienceLeft                      = "audience_left"
+	PlayerLatency                     = "player_latency"
 	GameStart                         = "game_start"
+	RoundStart                        = "round_start"
 	JobSubmitted                      = "job_submitted"
 	JobSubmittingFinished             = "player_job_submitting_finished"
 	ReceivedCards                     = "received_cards"
@@ -25,8 +40,12 @@ const (
 	CardData                          = "card_data"
 	InterceptionCardData              = "intercept_card_data"
 	TimerFinished                     = "timer_finished"
+	PhaseCountdown                    = "phase_countdown"
 	ScoreSubmission                   = "score_submission"
 	GameFinished                      = "game_finished"
+	PlayAgain                         = "play_again"
+	KickPlayer                        = "kick_player"
+	Kicked                            = "kicked"
 )
 
 // Generic communication message containing a message type
@@ -41,11 +60,61 @@ type LobbyCodeMessage struct {
 	LobbyCode *string `json:"lobby_code"`
 }
 
+// Message containing a lobby code and the secret the game client uses to reclaim the lobby after a disconnect.
+// Server -> Game
+type HostLobbyCodeMessage struct {
+	LobbyCodeMessage
+	HostSecret string `json:"host_secret"`
+}
+
+// Message containing information for game clients attempting to reclaim a lobby.
+// Game -> Server
+type HostRejoinMessage struct {
+	LobbyCodeMessage
+	HostSecret *string `json:"host_secret"`
+}
+
+// Represents a player's progress in the game at the time a snapshot was taken.
+type PlayerSnapshot struct {
+	Player
+	Connected              bool  `json:"connected"`
+	FinishedSubmittingJobs bool  `json:"finished_submitting_jobs"`
+	JobCard                *Card `json:"job_card"`
+	SelectedCard           *Card `json:"selected_card"`
+	ScoreInCents           int   `json:"score_in_cents"`
+	LatencyInMilliseconds  int   `json:"latency_ms"`
+}
+
+// Message containing the full state of a lobby, sent to game clients after they reclaim a lobby.
+// Server -> Game
+type LobbySnapshotMessage struct {
+	Message
+	LobbyCode              string            `json:"lobby_code"`
+	Phase                  string            `json:"phase"`
+	GameStarted            bool              `json:"game_started"`
+	NumberOfJobs           int               `json:"number_of_jobs"`
+	Players                []*PlayerSnapshot `json:"players"`
+	ImprovQueue            []uuid.UUID       `json:"improv_queue"`
+	CurrentImprovPlayerID  *uuid.UUID        `json:"current_improv_player_id"`
+	TimeRemainingInSeconds int               `json:"time_remaining_in_seconds"`
+	Paused                 bool              `json:"paused"`
+	AudienceSize           int               `json:"audience_size"`
+}
+
+// Message sent to clients when the host pauses or resumes the game, contains the time left on the running timer.
+// Server -> Web
+// Server -> Game
+type PauseStateMessage struct {
+	Message
+	TimeInSeconds int `json:"time_in_seconds"`
+}
+
 // Message containing information for web clients attempting to join a lobby.
 // Web -> Server
 type LobbyJoinAttemptMessage struct {
 	LobbyCodeMessage
-	Name *string `json:"name"`
+	Name     *string `json:"name"`
+	Audience bool    `json:"audience"`
 }
 
 // Message containing the connected player's ID.
@@ -55,6 +124,22 @@ type PlayerIDMessage struct {
 	PlayerID uuid.UUID `json:"player_id"`
 }
 
+// Message containing the connected player's ID and the token used to resume their session after a disconnect.
+// Audience is true if the client was admitted as an audience member, including players admitted to the audience of a full lobby.
+// Server -> Web
+type PlayerSessionMessage struct {
+	PlayerIDMessage
+	ResumeToken string `json:"resume_token"`
+	Audience    bool   `json:"audience"`
+}
+
+// Message containing information for web clients attempting to resume a session in a lobby.
+// Web -> Server
+type LobbyRejoinMessage struct {
+	LobbyCodeMessage
+	ResumeToken *string `json:"resume_token"`
+}
+
 // Represents a player with a UUID and a name.
 type Player struct {
 	PlayerID uuid.UUID `json:"player_id"`
@@ -65,7 +150,38 @@ type Player struct {
 // Server -> Game
 type PlayerJoinedMessage struct {
 	Message
-	Player Player `json:"player"`
+	Player    Player `json:"player"`
+	JudgeOnly bool   `json:"judge_only"`
+}
+
+// Message sent to game clients when an audience member joins or leaves the lobby, along with the audience's new size.
+// Server -> Game
+type AudienceUpdateMessage struct {
+	Message
+	Player       Player `json:"player"`
+	AudienceSize int    `json:"audience_size"`
+}
+
+// Message sent by the host to remove a player or audience member from the lobby, optionally banning them from returning.
+// Game -> Server
+type KickPlayerMessage struct {
+	Message
+	PlayerID *uuid.UUID `json:"player_id"`
+	Ban      bool       `json:"ban"`
+}
+
+// Message sent to a client that has been kicked from the lobby, just before its socket is closed.
+// Server -> Web
+type KickedMessage struct {
+	Message
+	Banned bool `json:"banned"`
+}
+
+// Message containing the measured round-trip latency of a player's connection.
+// Server -> Game
+type PlayerLatencyMessage struct {
+	PlayerIDMessage
+	LatencyInMilliseconds int `json:"latency_ms"`
 }
 
 // Message that acknowledges the start of the game for both web and game clients
@@ -76,6 +192,26 @@ type GameStartMessage struct {
 	NumberOfJobs int `json:"number_of_jobs"`
 }
 
+// Message sent to clients when another round of a multi-round game starts.
+// Players submit the number of jobs for the round before cards are dealt, or are dealt from the remaining jobs if it's zero.
+// Server -> Web
+// Server -> Game
+type RoundStartMessage struct {
+	Message
+	Round        int `json:"round"`
+	TotalRounds  int `json:"total_rounds"`
+	NumberOfJobs int `json:"number_of_jobs"`
+}
+
+// Message sent by game clients to create a lobby, a seed can be passed to reproduce the lobby's games
+// and a score aggregator to override the configured one for the lobby.
+// Game -> Server
+type CreateLobbyMessage struct {
+	Message
+	Seed            *int64  `json:"seed"`
+	ScoreAggregator *string `json:"score_aggregator"`
+}
+
 // Message containing the information sent by web clients for submitted jobs.
 // Web -> Server
 type JobSubmittedMessage struct {
@@ -93,15 +229,18 @@ type Card struct {
 // Server -> Web
 type ReceivedCardsMessage struct {
 	Message
-	DrawnCards []*Card `json:"drawn_cards"`
-	JobCard    *Card   `json:"job_card"`
+	DrawnCards  []*Card `json:"drawn_cards"`
+	JobCard     *Card   `json:"job_card"`
+	Round       int     `json:"round"`
+	TotalRounds int     `json:"total_rounds"`
 }
 
 // Message sent from web clients indicating that a player has selected their card.
-// Web -> Server
+// Also sent to web clients when a card is selected on their behalf.
+// Web -> Server / Server -> Web
 type CardDataMessage struct {
 	Message
-	Card *Card
+	Card *Card `json:"card"`
 }
 
 // Message sent to the game client indicating that a player has started improv.
@@ -111,21 +250,103 @@ type PlayerImprovStartMessage struct {
 	SelectedCard  *Card `json:"selected_card"`
 	JobCard       *Card `json:"job_card"`
 	TimeInSeconds int   `json:"time_in_seconds"`
+	Round         int   `json:"round"`
+	TotalRounds   int   `json:"total_rounds"`
+}
+
+// Message sent to clients when a phase with a deadline starts, or its deadline changes, so that they can render a countdown.
+// Server -> Web
+// Server -> Game
+type PhaseCountdownMessage struct {
+	Message
+	Phase         string `json:"phase"`
+	TimeInSeconds int    `json:"time_in_seconds"`
+	Paused        bool   `json:"paused"`
 }
 
 // Message sent to and from clients to represent the submission of salary scores in cents
+// When sent to the game, the score is the performer's cumulative score and the round score is what their last performance earned.
 // Web -> Server / Server -> Game
 type ScoreSubmissionMessage struct {
 	Message
+	ScoreInCents      int `json:"score_in_cents"`
+	RoundScoreInCents int `json:"round_score_in_cents"`
+}
+
+// Represents a player's standing once the game has finished.
+type PlayerResult struct {
+	Player
+	Rank                          int   `json:"rank"`
+	JobCard                       *Card `json:"job_card"`
+	SelectedCard                  *Card `json:"selected_card"`
+	ScoreInCents                  int   `json:"score_in_cents"`
+	AverageScoreInCents           int   `json:"average_score_in_cents"`
+	NumberOfScores                int   `json:"number_of_scores"`
+	NumberOfInterceptionsReceived int   `json:"interceptions_received"`
+	AudienceScoreInCents          int   `json:"audience_score_in_cents"`
+	NumberOfAudienceVotes         int   `json:"number_of_audience_votes"`
+}
+
+// Represents the player that received the highest average salary vote from the audience.
+type AudienceAward struct {
+	Player
+	AverageScoreInCents   int `json:"average_score_in_cents"`
+	NumberOfAudienceVotes int `json:"number_of_audience_votes"`
+}
+
+// Represents the outcome of a single improv performance.
+type RoundResult struct {
+	Performance           int                   `json:"performance"`
+	Round                 int                   `json:"round"`
+	PlayerID              uuid.UUID             `json:"player_id"`
+	SelectedCard          *Card                 `json:"selected_card"`
+	ScoreInCents          int                   `json:"score_in_cents"`
+	NumberOfScores        int                   `json:"number_of_scores"`
+	NumberOfInterceptions int                   `json:"interceptions"`
+	InterceptedBy         []*InterceptionResult `json:"intercepted_by"`
+	Skipped               bool                  `json:"skipped"`
+}
+
+// Represents an interception made during an improv performance.
+type InterceptionResult struct {
+	Player
+	Card *Card `json:"card"`
+}
+
+// Represents a player's standing across every game played in a lobby since scores were last cleared.
+type SessionResult struct {
+	Player
+	Rank         int `json:"rank"`
 	ScoreInCents int `json:"score_in_cents"`
+	GamesPlayed  int `json:"games_played"`
+}
+
+// Message sent to clients once the game has finished, contains the players ranked by score and a breakdown of each round.
+// Server -> Web
+// Server -> Game
+type GameFinishedMessage struct {
+	Message
+	Rankings        []*PlayerResult  `json:"rankings"`
+	Rounds          []*RoundResult   `json:"rounds"`
+	SessionRankings []*SessionResult `json:"session_rankings"`
+	AudienceAward   *AudienceAward   `json:"audience_award"`
+}
+
+// Message sent by the host to start a new game in the same lobby once the current game has finished.
+// Game -> Server
+type PlayAgainMessage struct {
+	Message
+	KeepScores bool `json:"keep_scores"`
 }
 
 // Message sent from the server to game clients to represent a card interception being played.
 // Server -> Game
 type InterceptionCardMessage struct {
 	PlayerIDMessage
-	InterceptedCard *Card `json:"intercepted_card"`
-	TimeInSeconds   int   `json:"time_in_seconds"`
+	Name            *string   `json:"name"`
+	PerformerID     uuid.UUID `json:"performer_id"`
+	InterceptedCard *Card     `json:"intercepted_card"`
+	TimeInSeconds   int       `json:"time_in_seconds"`
 }
 
 // Creates a Message.
@@ -148,18 +369,71 @@ func CreateLobbyCodeMessage(lc *string) *LobbyCodeMessage {
 	}
 }
 
-// Verifies the integrity of the `LobbyJoinAttemptMessage`, reports errors as required
-func (l *LobbyJoinAttemptMessage) Verify(lc *string) error {
+// Creates a HostLobbyCodeMessage.
+func CreateHostLobbyCodeMessage(lc *string, hs string) *HostLobbyCodeMessage {
+	return &HostLobbyCodeMessage{
+		LobbyCodeMessage: *CreateLobbyCodeMessage(lc),
+		HostSecret:       hs,
+	}
+}
+
+// Verifies the integrity of the `HostRejoinMessage`, reports errors as required
+func (h *HostRejoinMessage) Verify() error {
+	if h.LobbyCode == nil {
+		return NewCodedError(ErrorLobbyCodeMissing, "Host rejoin request was received, but no lobby code was specified.")
+	}
+
+	if h.HostSecret == nil {
+		return NewCodedError(ErrorHostSecretMissing, "Host rejoin request was received, but no host secret was specified.")
+	}
+
+	return nil
+}
+
+// Creates a PauseStateMessage.
+func CreatePauseStateMessage(mt MessageType, t int) *PauseStateMessage {
+	return &PauseStateMessage{
+		Message:       *CreateBasicMessage(mt),
+		TimeInSeconds: t,
+	}
+}
+
+// Creates and marshals a PauseStateMessage.
+func MarshalPauseStateMessage(mt MessageType, t int) []byte {
+	return json.MarshalJSONBytes[PauseStateMessage](CreatePauseStateMessage(mt, t))
+}
+
+// Creates and marshals a LobbySnapshotMessage.
+func MarshalLobbySnapshotMessage(lsm *LobbySnapshotMessage) []byte {
+	lsm.Message = *CreateBasicMessage(LobbySnapshot)
+	return json.MarshalJSONBytes[LobbySnapshotMessage](lsm)
+}
+
+// Verifies the integrity of the `LobbyJoinAttemptMessage`, reports errors as required.
+// The player name is sanitized in place and must be within the passed length limits, measured in characters.
+func (l *LobbyJoinAttemptMessage) Verify(lc *string, minNameLength int, maxNameLength int) error {
 	if l.LobbyCode == nil {
-		return errors.New("Lobby join request was received, but no lobby code was specified.")
+		return NewCodedError(ErrorLobbyCodeMissing, "Lobby join request was received, but no lobby code was specified.")
 	}
 
 	if l.Name == nil {
-		return errors.New("Lobby join request was received, but no player name was specified.")
+		return NewCodedError(ErrorNameMissing, "Lobby join request was received, but no player name was specified.")
+	}
+
+	name := SanitizeText(*l.Name)
+	l.Name = &name
+
+	n := utf8.RuneCountInString(name)
+	if n == 0 || n < minNameLength {
+		return NewCodedErrorf(ErrorNameTooShort, "Lobby join request was received, but the player name must be at least %d characters.", minNameLength)
+	}
+
+	if n > maxNameLength {
+		return NewCodedErrorf(ErrorNameTooLong, "Lobby join request was received, but the player name must be at most %d characters.", maxNameLength)
 	}
 
 	if *l.LobbyCode != *lc {
-		return errors.New("Lobby join request was received, but the lobby code was incorrect.")
+		return NewCodedError(ErrorWrongLobbyCode, "Lobby join request was received, but the lobby code was incorrect.")
 	}
 
 	return nil
@@ -178,6 +452,27 @@ func MarshalPlayerIDMessage(mt MessageType, uuid *uuid.UUID) []byte {
 	return json.MarshalJSONBytes[PlayerIDMessage](CreatePlayerIDMessage(mt, uuid))
 }
 
+// Creates a PlayerSessionMessage.
+func CreatePlayerSessionMessage(uuid *uuid.UUID, rt string) *PlayerSessionMessage {
+	return &PlayerSessionMessage{
+		PlayerIDMessage: *CreatePlayerIDMessage(PlayerID, uuid),
+		ResumeToken:     rt,
+	}
+}
+
+// Verifies the integrity of the `LobbyRejoinMessage`, reports errors as required
+func (l *LobbyRejoinMessage) Verify() error {
+	if l.LobbyCode == nil {
+		return NewCodedError(ErrorLobbyCodeMissing, "Lobby rejoin request was received, but no lobby code was specified.")
+	}
+
+	if l.ResumeToken == nil {
+		return NewCodedError(ErrorResumeTokenMissing, "Lobby rejoin request was received, but no resume token was specified.")
+	}
+
+	return nil
+}
+
 // Creates a Player.
 func CreatePlayer(uuid *uuid.UUID, name *string) *Player {
 	return &Player{
@@ -194,6 +489,40 @@ func CreatePlayerJoinedMessage(uuid *uuid.UUID, name *string) *PlayerJoinedMessa
 	}
 }
 
+// Creates and marshals an AudienceUpdateMessage of the passed type.
+func MarshalAudienceUpdateMessage(mt MessageType, uuid *uuid.UUID, name *string, n int) []byte {
+	return json.MarshalJSONBytes[AudienceUpdateMessage](&AudienceUpdateMessage{
+		Message:      *CreateBasicMessage(mt),
+		Player:       *CreatePlayer(uuid, name),
+		AudienceSize: n,
+	})
+}
+
+// Verifies the integrity of the `KickPlayerMessage`, reports errors as required.
+func (k *KickPlayerMessage) Verify() error {
+	if k.PlayerID == nil {
+		return NewCodedError(ErrorPlayerIDMissing, "Kick request was received, but no player ID was specified.")
+	}
+
+	return nil
+}
+
+// Creates and marshals a KickedMessage.
+func MarshalKickedMessage(banned bool) []byte {
+	return json.MarshalJSONBytes[KickedMessage](&KickedMessage{
+		Message: *CreateBasicMessage(Kicked),
+		Banned:  banned,
+	})
+}
+
+// Creates and marshals a PlayerLatencyMessage.
+func MarshalPlayerLatencyMessage(uuid *uuid.UUID, ms int) []byte {
+	return json.MarshalJSONBytes[PlayerLatencyMessage](&PlayerLatencyMessage{
+		PlayerIDMessage:       *CreatePlayerIDMessage(PlayerLatency, uuid),
+		LatencyInMilliseconds: ms,
+	})
+}
+
 // Creates a GameStartMessage.
 func CreateGameStartMessage(n int) *GameStartMessage {
 	return &GameStartMessage{
@@ -202,84 +531,162 @@ func CreateGameStartMessage(n int) *GameStartMessage {
 	}
 }
 
-// Verifies the integrity of the `JobSubmittedMessage`, reports errors as required
-func (j *JobSubmittedMessage) Verify() error {
+// Verifies the integrity of the `ScoreSubmissionMessage`, the score must be within the passed salary range.
+func (ss *ScoreSubmissionMessage) Verify(minScoreInCents int, maxScoreInCents int) error {
+	if ss.ScoreInCents < minScoreInCents || ss.ScoreInCents > maxScoreInCents {
+		return NewCodedErrorf(ErrorScoreOutOfRange, "Score submission request was received, but the score must be between %d and %d cents.", minScoreInCents, maxScoreInCents)
+	}
+
+	return nil
+}
+
+// Verifies the integrity of the `JobSubmittedMessage`, reports errors as required.
+// The job is sanitized in place and must be within the passed length limits, measured in characters.
+func (j *JobSubmittedMessage) Verify(minJobLength int, maxJobLength int) error {
 	if j.JobInput == nil {
-		return errors.New("Job submission request was received, but no job was specified.")
+		return NewCodedError(ErrorJobMissing, "Job submission request was received, but no job was specified.")
+	}
+
+	job := SanitizeText(*j.JobInput)
+	j.JobInput = &job
+
+	n := utf8.RuneCountInString(job)
+	if n == 0 || n < minJobLength {
+		return NewCodedErrorf(ErrorJobTooShort, "Job submission request was received, but the job must be at least %d characters.", minJobLength)
+	}
+
+	if n > maxJobLength {
+		return NewCodedErrorf(ErrorJobTooLong, "Job submission request was received, but the job must be at most %d characters.", maxJobLength)
 	}
 
 	return nil
 }
 
 // Creates a ReceivedCardsMessage.
-func CreateReceivedCardsMessage(dc []*Card, jc *Card) *ReceivedCardsMessage {
+func CreateReceivedCardsMessage(dc []*Card, jc *Card, round int, totalRounds int) *ReceivedCardsMessage {
 	return &ReceivedCardsMessage{
-		Message:    *CreateBasicMessage(ReceivedCards),
-		DrawnCards: dc,
-		JobCard:    jc,
+		Message:     *CreateBasicMessage(ReceivedCards),
+		DrawnCards:  dc,
+		JobCard:     jc,
+		Round:       round,
+		TotalRounds: totalRounds,
 	}
 }
 
 // Creates and marshals a ReceivedCardsMessage.
-func MarshalReceivedCardsMessage(dc []*Card, jc *Card) []byte {
-	return json.MarshalJSONBytes[ReceivedCardsMessage](CreateReceivedCardsMessage(dc, jc))
+func MarshalReceivedCardsMessage(dc []*Card, jc *Card, round int, totalRounds int) []byte {
+	return json.MarshalJSONBytes[ReceivedCardsMessage](CreateReceivedCardsMessage(dc, jc, round, totalRounds))
+}
+
+// Creates and marshals a RoundStartMessage.
+func MarshalRoundStartMessage(round int, totalRounds int, n int) []byte {
+	return json.MarshalJSONBytes[RoundStartMessage](&RoundStartMessage{
+		Message:      *CreateBasicMessage(RoundStart),
+		Round:        round,
+		TotalRounds:  totalRounds,
+		NumberOfJobs: n,
+	})
 }
 
 // Verifies the integrity of the `CardDataMessage`, reports errors as required.
 func (c *CardDataMessage) Verify() error {
 	if c.Card == nil {
-		return errors.New("Card submission request was received, but no card was specfied.")
+		return NewCodedError(ErrorCardMissing, "Card submission request was received, but no card was specfied.")
 	}
 
 	if err := uuid.Validate(c.Card.CardID.String()); err != nil {
-		return errors.New("Card submission request was receieved, but the card had a malformed UUID.")
+		return NewCodedError(ErrorMalformedCard, "Card submission request was receieved, but the card had a malformed UUID.")
 	}
 
 	if c.Card.JobText == nil {
-		return errors.New("Card submission request was received, but the card had malformed text.")
+		return NewCodedError(ErrorMalformedCard, "Card submission request was received, but the card had malformed text.")
 	}
 
 	return nil
 }
 
+// Creates and marshals a CardDataMessage.
+func MarshalCardDataMessage(c *Card) []byte {
+	return json.MarshalJSONBytes[CardDataMessage](&CardDataMessage{
+		Message: *CreateBasicMessage(CardData),
+		Card:    c,
+	})
+}
+
+// Creates a PhaseCountdownMessage.
+func CreatePhaseCountdownMessage(phase string, t int, paused bool) *PhaseCountdownMessage {
+	return &PhaseCountdownMessage{
+		Message:       *CreateBasicMessage(PhaseCountdown),
+		Phase:         phase,
+		TimeInSeconds: t,
+		Paused:        paused,
+	}
+}
+
+// Creates and marshals a PhaseCountdownMessage.
+func MarshalPhaseCountdownMessage(phase string, t int, paused bool) []byte {
+	return json.MarshalJSONBytes[PhaseCountdownMessage](CreatePhaseCountdownMessage(phase, t, paused))
+}
+
 // Creates a PlayerImprovStartMessage.
-func CreatePlayerImprovStartMessage(uuid *uuid.UUID, sc *Card, jc *Card, t int) *PlayerImprovStartMessage {
+func CreatePlayerImprovStartMessage(uuid *uuid.UUID, sc *Card, jc *Card, t int, round int, totalRounds int) *PlayerImprovStartMessage {
 	return &PlayerImprovStartMessage{
 		PlayerIDMessage: *CreatePlayerIDMessage(PlayerImprovStart, uuid),
 		SelectedCard:    sc,
 		JobCard:         jc,
 		TimeInSeconds:   t,
+		Round:           round,
+		TotalRounds:     totalRounds,
 	}
 }
 
 // Creates and marshals a PlayerImprovStartMessage.
-func MarshalPlayerImprovStartMessage(uuid *uuid.UUID, sc *Card, jc *Card, t int) []byte {
-	return json.MarshalJSONBytes[PlayerImprovStartMessage](CreatePlayerImprovStartMessage(uuid, sc, jc, t))
+func MarshalPlayerImprovStartMessage(uuid *uuid.UUID, sc *Card, jc *Card, t int, round int, totalRounds int) []byte {
+	return json.MarshalJSONBytes[PlayerImprovStartMessage](CreatePlayerImprovStartMessage(uuid, sc, jc, t, round, totalRounds))
 }
 
 // Creates a ScoreSubmissionMessage.
-func CreateScoreSubmissionMessage(sc int) *ScoreSubmissionMessage {
+func CreateScoreSubmissionMessage(sc int, rsc int) *ScoreSubmissionMessage {
 	return &ScoreSubmissionMessage{
-		Message:      *CreateBasicMessage(ScoreSubmission),
-		ScoreInCents: sc,
+		Message:           *CreateBasicMessage(ScoreSubmission),
+		ScoreInCents:      sc,
+		RoundScoreInCents: rsc,
 	}
 }
 
 // Creates and marshals a ScoreSubmissionMessage.
-func MarshalScoreSubmissionMessage(sc int) []byte {
-	return json.MarshalJSONBytes[ScoreSubmissionMessage](CreateScoreSubmissionMessage(sc))
+func MarshalScoreSubmissionMessage(sc int, rsc int) []byte {
+	return json.MarshalJSONBytes[ScoreSubmissionMessage](CreateScoreSubmissionMessage(sc, rsc))
 }
 
 // Creates an InterceptionCardMessage.
-func CreateInterceptionCardMessage(uuid *uuid.UUID, c *Card, t int) *InterceptionCardMessage {
+func CreateInterceptionCardMessage(uuid *uuid.UUID, name *string, performer *uuid.UUID, c *Card, t int) *InterceptionCardMessage {
 	return &InterceptionCardMessage{
 		PlayerIDMessage: *CreatePlayerIDMessage(InterceptionCardData, uuid),
+		Name:            name,
+		PerformerID:     *performer,
 		InterceptedCard: c,
 		TimeInSeconds:   t,
 	}
 }
 
 // Creates and marshals an InterceptionCardMessage,
-func MarshalInterceptionCardMessage(uuid *uuid.UUID, c *Card, t int) []byte {
-	return json.MarshalJSONBytes[InterceptionCardMessage](CreateInterceptionCardMessage(uuid, c, t))
+func MarshalInterceptionCardMessage(uuid *uuid.UUID, name *string, performer *uuid.UUID, c *Card, t int) []byte {
+	return json.MarshalJSONBytes[InterceptionCardMessage](CreateInterceptionCardMessage(uuid, name, performer, c, t))
+}
+
+// Creates a GameFinishedMessage.
+func CreateGameFinishedMessage(rankings []*PlayerResult, rounds []*RoundResult, sessionRankings []*SessionResult, aa *AudienceAward) *GameFinishedMessage {
+	return &GameFinishedMessage{
+		Message:         *CreateBasicMessage(GameFinished),
+		Rankings:        rankings,
+		Rounds:          rounds,
+		SessionRankings: sessionRankings,
+		AudienceAward:   aa,
+	}
+}
+
+// Creates and marshals a GameFinishedMessage.
+func MarshalGameFinishedMessage(rankings []*PlayerResult, rounds []*RoundResult, sessionRankings []*SessionResult, aa *AudienceAward) []byte {
+	return json.MarshalJSONBytes[GameFinishedMessage](CreateGameFinishedMessage(rankings, rounds, sessionRankings, aa))
 }
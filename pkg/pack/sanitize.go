@@ -0,0 +1,29 @@
+package pack
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Sanitizes player submitted text such as names and jobs, normalizing it to NFC, stripping control and format characters and collapsing whitespace.
+func SanitizeText(text string) string {
+	text = norm.NFC.String(strings.ToValidUTF8(text, ""))
+
+	// Control and bidi override characters can break or spoof how text is rendered,
+	// and invisible format characters such as zero-width spaces can pass off a blank name or a lookalike of another player's name
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r) {
+			return ' '
+		}
+
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+
+		return r
+	}, text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,43 @@
+package pack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "Sam", want: "Sam"},
+		{name: "whitespace", text: "  Sam \t  Smith\n", want: "Sam Smith"},
+		{name: "control characters", text: "Sam\x00Smith", want: "Sam Smith"},
+		{name: "bidi override", text: "Sam\u202eSmith", want: "Sam Smith"},
+		{name: "zero-width characters", text: "S\u200bam\u2060\ufeff", want: "Sam"},
+		{name: "only zero-width characters", text: "\u200b\u200c\u2060", want: ""},
+		{name: "invalid UTF-8", text: "Sam\xff", want: "Sam"},
+		{name: "decomposed accent", text: "Jose\u0301", want: "José"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.text); got != tt.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLobbyJoinAttemptRejectsZeroWidthName(t *testing.T) {
+	lc := "ABCD"
+	name := "\u200b\u2060"
+	ljam := &LobbyJoinAttemptMessage{Name: &name}
+	ljam.LobbyCode = &lc
+
+	var ce *CodedError
+	if err := ljam.Verify(&lc, 1, 20); !errors.As(err, &ce) || ce.Code != ErrorNameTooShort {
+		t.Fatalf("joining with a zero-width name returned %v, want %s", err, ErrorNameTooShort)
+	}
+}